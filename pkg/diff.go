@@ -0,0 +1,218 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// Change represents the kind of modifications made on a path between two directories.
+type Change uint8
+
+const (
+	// ChangeContent indicates that files content (or symbolic links target) differs.
+	ChangeContent Change = 1 << iota
+	// ChangeMode indicates that permission bits differ (only when compared, see WithModes).
+	ChangeMode
+	// ChangeType indicates that types differ (e.g. a file in one directory and a directory in the other).
+	ChangeType
+)
+
+// DiffEntry represents a path present in at least one of two compared directories.
+type DiffEntry struct {
+	// Path is the path relative to compared directories.
+	Path string
+	// A is the path information in first directory, nil when it's not present.
+	A fs.FileInfo
+	// B is the path information in second directory, nil when it's not present.
+	B fs.FileInfo
+	// Changes are the modifications between A and B, zero when the path isn't modified.
+	Changes Change
+}
+
+// DirDiff represents the differences between two directories, all maps are keyed by relative paths.
+type DirDiff struct {
+	// Added contains paths only present in second directory.
+	Added map[string]DiffEntry
+	// Removed contains paths only present in first directory.
+	Removed map[string]DiffEntry
+	// Modified contains paths present in both directories but with differences (see Change).
+	Modified map[string]DiffEntry
+	// Unchanged contains paths present in both directories without any difference.
+	Unchanged map[string]DiffEntry
+}
+
+// Equal returns true if both compared directories are identical (nothing added, removed or modified).
+func (d *DirDiff) Equal() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// WithModes specifies that DiffDir and DiffFS must compare permission bits.
+//
+// It's disabled by default since FS don't report the same permissions for the same files
+// (e.g. embed.FS reports 0o444, fstest.MapFS reports its given mode and OS FS reports actual permissions).
+func WithModes() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.modes = true
+	}
+}
+
+// DiffDir compares recursively two directories a and b from the same FS (see WithFS).
+//
+// See DiffFS for more details.
+func DiffDir(a, b string, opts ...FSOption) (*DirDiff, error) {
+	o := newFSOpt(opts...)
+	return DiffFS(o.fsys, a, o.fsys, b, opts...)
+}
+
+// DiffFS compares recursively the directory a from afs and the directory b from bfs.
+//
+// Files and directories are compared by type, content for files, target for symbolic links (when both FS support them)
+// and permission bits when asked (see WithModes).
+// Options like WithJoin, WithFilter or WithSymlinks are taken into account to walk both directories.
+func DiffFS(afs FS, a string, bfs FS, b string, opts ...FSOption) (*DirDiff, error) {
+	o := newFSOpt(opts...)
+
+	ainfos, err := walkInfos(afs, a, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", a, err)
+	}
+	binfos, err := walkInfos(bfs, b, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", b, err)
+	}
+
+	diff := &DirDiff{
+		Added:     map[string]DiffEntry{},
+		Removed:   map[string]DiffEntry{},
+		Modified:  map[string]DiffEntry{},
+		Unchanged: map[string]DiffEntry{},
+	}
+	for path, binfo := range binfos {
+		if _, ok := ainfos[path]; !ok {
+			diff.Added[path] = DiffEntry{Path: path, B: binfo}
+		}
+	}
+
+	var errs []error
+	for path, ainfo := range ainfos {
+		binfo, ok := binfos[path]
+		if !ok {
+			diff.Removed[path] = DiffEntry{Path: path, A: ainfo}
+			continue
+		}
+
+		entry := DiffEntry{Path: path, A: ainfo, B: binfo}
+		if ainfo.Mode().Type() != binfo.Mode().Type() {
+			entry.Changes |= ChangeType
+		} else {
+			if o.modes && ainfo.Mode().Perm() != binfo.Mode().Perm() {
+				entry.Changes |= ChangeMode
+			}
+			same, err := sameContent(afs, o.join(a, path), bfs, o.join(b, path), ainfo, binfo)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to compare %s: %w", path, err))
+				continue
+			}
+			if !same {
+				entry.Changes |= ChangeContent
+			}
+		}
+
+		if entry.Changes == 0 {
+			diff.Unchanged[path] = entry
+		} else {
+			diff.Modified[path] = entry
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return diff, nil
+}
+
+// walkInfos walks root in fsys and returns all visited paths with their information.
+func walkInfos(fsys FS, root string, opts ...FSOption) (map[string]fs.FileInfo, error) {
+	infos := map[string]fs.FileInfo{}
+	err := Walk(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		infos[path] = info
+		return nil
+	}, append(opts, WithFS(fsys))...)
+	return infos, err
+}
+
+// sameContent returns true if aname and bname have the same content (same target for symbolic links).
+//
+// Directories always have the same content.
+func sameContent(afs FS, aname string, bfs FS, bname string, ainfo, binfo fs.FileInfo) (bool, error) {
+	switch {
+	case ainfo.IsDir():
+		return true, nil
+	case ainfo.Mode()&fs.ModeSymlink != 0:
+		alinks, aok := afs.(readLinkFS)
+		blinks, bok := bfs.(readLinkFS)
+		if !aok || !bok {
+			return true, nil // targets can't be compared
+		}
+		atarget, err := alinks.ReadLink(aname)
+		if err != nil {
+			return false, err
+		}
+		btarget, err := blinks.ReadLink(bname)
+		if err != nil {
+			return false, err
+		}
+		return atarget == btarget, nil
+	case !ainfo.Mode().IsRegular():
+		return true, nil // devices, pipes, sockets, etc. content isn't relevant
+	case ainfo.Size() != binfo.Size():
+		return false, nil
+	}
+
+	afile, err := afs.Open(aname)
+	if err != nil {
+		return false, err
+	}
+	defer afile.Close()
+
+	bfile, err := bfs.Open(bname)
+	if err != nil {
+		return false, err
+	}
+	defer bfile.Close()
+
+	return sameReaders(afile, bfile)
+}
+
+// sameReaders returns true if both readers have the same content.
+func sameReaders(a, b io.Reader) (bool, error) {
+	abuf := make([]byte, 32*1024)
+	bbuf := make([]byte, len(abuf))
+	for {
+		an, aerr := io.ReadFull(a, abuf)
+		bn, berr := io.ReadFull(b, bbuf)
+		if !bytes.Equal(abuf[:an], bbuf[:bn]) {
+			return false, nil
+		}
+
+		aeof := errors.Is(aerr, io.EOF) || errors.Is(aerr, io.ErrUnexpectedEOF)
+		beof := errors.Is(berr, io.EOF) || errors.Is(berr, io.ErrUnexpectedEOF)
+		switch {
+		case aerr != nil && !aeof:
+			return false, aerr
+		case berr != nil && !beof:
+			return false, berr
+		case aeof || beof:
+			return aeof == beof, nil
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package filesystem_test
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+// keys returns the sorted keys of a DirDiff map.
+func keys(entries map[string]filesystem.DiffEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestDiffFS(t *testing.T) {
+	a := fstest.MapFS{
+		"same.txt":        &fstest.MapFile{Data: []byte("same"), Mode: 0o644},
+		"content.txt":     &fstest.MapFile{Data: []byte("content a"), Mode: 0o644},
+		"size.txt":        &fstest.MapFile{Data: []byte("size"), Mode: 0o644},
+		"mode.sh":         &fstest.MapFile{Data: []byte("echo"), Mode: 0o755},
+		"type":            &fstest.MapFile{Data: []byte("file"), Mode: 0o644},
+		"removed/old.txt": &fstest.MapFile{Data: []byte("old"), Mode: 0o644},
+		"dir":             &fstest.MapFile{Mode: fs.ModeDir | 0o755},
+	}
+	b := fstest.MapFS{
+		"same.txt":      &fstest.MapFile{Data: []byte("same"), Mode: 0o644},
+		"content.txt":   &fstest.MapFile{Data: []byte("content b"), Mode: 0o644},
+		"size.txt":      &fstest.MapFile{Data: []byte("bigger size"), Mode: 0o644},
+		"mode.sh":       &fstest.MapFile{Data: []byte("echo"), Mode: 0o644},
+		"type/file.txt": &fstest.MapFile{Data: []byte("file"), Mode: 0o644},
+		"added.txt":     &fstest.MapFile{Data: []byte("new"), Mode: 0o644},
+		"dir":           &fstest.MapFile{Mode: fs.ModeDir | 0o755},
+	}
+
+	t.Run("error_walk", func(t *testing.T) {
+		// Act
+		_, err := filesystem.DiffFS(a, "invalid", b, ".")
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		diff, err := filesystem.DiffFS(a, ".", b, ".", filesystem.WithJoin(path.Join), filesystem.WithModes())
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, diff.Equal())
+		assert.Equal(t, []string{"added.txt", "type/file.txt"}, keys(diff.Added))
+		assert.Equal(t, []string{"removed", "removed/old.txt"}, keys(diff.Removed))
+		assert.Equal(t, []string{"content.txt", "mode.sh", "size.txt", "type"}, keys(diff.Modified))
+		assert.Equal(t, []string{"dir", "same.txt"}, keys(diff.Unchanged))
+
+		assert.Equal(t, filesystem.ChangeContent, diff.Modified["content.txt"].Changes)
+		assert.Equal(t, filesystem.ChangeContent, diff.Modified["size.txt"].Changes)
+		assert.Equal(t, filesystem.ChangeMode, diff.Modified["mode.sh"].Changes)
+		assert.Equal(t, filesystem.ChangeType, diff.Modified["type"].Changes)
+		assert.Nil(t, diff.Added["added.txt"].A)
+		assert.NotNil(t, diff.Added["added.txt"].B)
+	})
+
+	t.Run("success_no_modes", func(t *testing.T) {
+		// Act
+		diff, err := filesystem.DiffFS(a, ".", b, ".", filesystem.WithJoin(path.Join))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"content.txt", "size.txt", "type"}, keys(diff.Modified))
+		assert.Contains(t, keys(diff.Unchanged), "mode.sh")
+	})
+
+	t.Run("success_filter", func(t *testing.T) {
+		// Act
+		diff, err := filesystem.DiffFS(a, ".", b, ".", filesystem.WithJoin(path.Join), filesystem.WithInclude("same.txt", "dir"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, diff.Equal())
+		assert.Len(t, diff.Unchanged, 2)
+	})
+}
+
+func TestDiffFS_OS(t *testing.T) {
+	// Arrange
+	expected := fstest.MapFS{
+		"file.txt":       &fstest.MapFile{Data: []byte("hey file")},
+		"dir/nested.txt": &fstest.MapFile{Data: []byte("hey nested")},
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hey file"), filesystem.RwRR))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dir"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dir", "nested.txt"), []byte("hey nested"), filesystem.RwRR))
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		diff, err := filesystem.DiffFS(expected, ".", filesystem.OS(), dir, filesystem.WithJoin(path.Join))
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, diff.Equal())
+		assert.Equal(t, []string{"dir", "dir/nested.txt", "file.txt"}, keys(diff.Unchanged))
+	})
+
+	t.Run("success_modes", func(t *testing.T) {
+		// Act
+		diff, err := filesystem.DiffFS(expected, ".", filesystem.OS(), dir, filesystem.WithJoin(path.Join), filesystem.WithModes())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dir", "dir/nested.txt", "file.txt"}, keys(diff.Modified))
+		assert.Equal(t, filesystem.ChangeMode, diff.Modified["file.txt"].Changes)
+	})
+}
+
+func TestDiffDir(t *testing.T) {
+	t.Run("success_copy", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "file.txt"), []byte("hey file"), filesystem.RwRR))
+		require.NoError(t, os.Mkdir(filepath.Join(srcdir, "empty"), filesystem.RwxRxRxRx))
+		destdir := filepath.Join(t.TempDir(), "dest")
+		require.NoError(t, filesystem.CopyDir(srcdir, destdir))
+
+		// Act
+		diff, err := filesystem.DiffDir(srcdir, destdir)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, diff.Equal())
+		assert.Equal(t, []string{"empty", "file.txt"}, keys(diff.Unchanged))
+	})
+
+	t.Run("success_symlinks", func(t *testing.T) {
+		// Arrange
+		a := t.TempDir()
+		b := t.TempDir()
+		if err := os.Symlink("target_a", filepath.Join(a, "link")); err != nil {
+			t.Skip("symbolic links not supported:", err)
+		}
+		require.NoError(t, os.Symlink("target_b", filepath.Join(b, "link")))
+		require.NoError(t, os.Symlink("same", filepath.Join(a, "same")))
+		require.NoError(t, os.Symlink("same", filepath.Join(b, "same")))
+
+		// Act
+		diff, err := filesystem.DiffDir(a, b)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"link"}, keys(diff.Modified))
+		assert.Equal(t, []string{"same"}, keys(diff.Unchanged))
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, filesystem.ChangeContent, diff.Modified["link"].Changes)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 /*
 The filesystem package exposes some useful function around files,
 for instance a simple function as `func Exists(src) bool` to verify a file existence easily.
+`CheckExists(src) (bool, error)`, `IsFile(src) bool`, `IsDir(src) bool` and `IsSymlink(src) bool`
+are also available to distinguish errors and file types.
 
 It also exposes `CopyFile(src, dest) error` and `CopyFileWithPerm(src, dest, perm) error`
 which copy a given src file to dst with either specific permissions or not.
@@ -8,6 +10,21 @@ which copy a given src file to dst with either specific permissions or not.
 It also exposes `CopyDir(srcdir, destdir)` to copy a full directory at another place.
 The destination directory will be created if it doesn't already.
 
+It also exposes `Walk(root, fn)` to walk recursively a directory with filters, max depth, symbolic links policies
+and either pre-order or post-order visits.
+
+It also exposes `Glob(root, pattern)` and `Match(pattern, name)` supporting `**`, character classes,
+brace expansion and case-insensitive matching.
+
+It also exposes `DiffDir(a, b)` and `DiffFS(afs, a, bfs, b)` to compare two directories
+and retrieve added, removed, modified and unchanged paths (permission bits are only compared with `WithModes()`).
+
+It also exposes `Usage(root)` to compute files count, directories count, apparent size and allocated blocks
+of a directory tree, per top-level directory and per extension.
+
+It also exposes `Watch(ctx, root, fn)` to watch recursively a directory for changes with debounced batches of events,
+using inotify on Linux and polling elsewhere.
+
 The package also exposes some constants around permissions.
 */
 package filesystem
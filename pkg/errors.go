@@ -0,0 +1,95 @@
+package filesystem
+
+import "fmt"
+
+// Op represents an operation made on a file or a directory during CopyFile or CopyDir.
+type Op string
+
+const (
+	// OpOpen is the operation of opening a source file.
+	OpOpen Op = "open"
+	// OpCreate is the operation of creating a destination file.
+	OpCreate Op = "create"
+	// OpCopy is the operation of copying a source file content into its destination.
+	OpCopy Op = "copy"
+	// OpChmod is the operation of updating a destination file permissions.
+	OpChmod Op = "chmod"
+	// OpChown is the operation of updating a destination file or directory owner.
+	OpChown Op = "chown"
+	// OpXattr is the operation of copying extended attributes of a source file into its destination.
+	OpXattr Op = "xattr"
+	// OpMkdir is the operation of creating a destination directory.
+	OpMkdir Op = "mkdir"
+	// OpReadDir is the operation of reading a source directory entries.
+	OpReadDir Op = "readdir"
+	// OpBackup is the operation of backing up an existing destination file before overwriting it in a transactional CopyDir.
+	OpBackup Op = "backup"
+)
+
+// CopyError represents the failure of a single operation made during CopyFile or CopyDir.
+type CopyError struct {
+	// Op is the operation that failed.
+	Op Op
+	// Src is the source path (file or directory) involved in the operation.
+	Src string
+	// Dest is the destination path (file or directory) involved in the operation.
+	Dest string
+	// Err is the underlying cause of the failure.
+	Err error
+}
+
+var _ error = (*CopyError)(nil) // ensure interface is implemented
+
+// Error returns the string representation of CopyError.
+func (e *CopyError) Error() string {
+	switch e.Op {
+	case OpOpen:
+		return fmt.Sprintf("failed to read %s: %v", e.Src, e.Err)
+	case OpCreate:
+		return fmt.Sprintf("failed to create %s: %v", e.Dest, e.Err)
+	case OpCopy:
+		return fmt.Sprintf("failed to copy file %s to %s: %v", e.Src, e.Dest, e.Err)
+	case OpChmod:
+		return fmt.Sprintf("failed to update %s permissions: %v", e.Dest, e.Err)
+	case OpChown:
+		return fmt.Sprintf("failed to update %s owner: %v", e.Dest, e.Err)
+	case OpXattr:
+		return fmt.Sprintf("failed to copy extended attributes of %s to %s: %v", e.Src, e.Dest, e.Err)
+	case OpMkdir:
+		return fmt.Sprintf("failed to create folder %s: %v", e.Dest, e.Err)
+	case OpReadDir:
+		return fmt.Sprintf("failed to read directory %s: %v", e.Src, e.Err)
+	case OpBackup:
+		return fmt.Sprintf("failed to backup %s: %v", e.Dest, e.Err)
+	default:
+		return fmt.Sprintf("failed to %s %s to %s: %v", e.Op, e.Src, e.Dest, e.Err)
+	}
+}
+
+// Unwrap returns the underlying cause of CopyError.
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+// CopyErrors returns all CopyError contained in err,
+// err being the (joined) error returned by CopyFile or CopyDir.
+//
+// It returns nil if err is nil or if it doesn't contain any CopyError.
+func CopyErrors(err error) []*CopyError {
+	if err == nil {
+		return nil
+	}
+
+	var errs []*CopyError
+	switch err := err.(type) {
+	case *CopyError:
+		errs = append(errs, err)
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			errs = append(errs, CopyErrors(err)...)
+		}
+	case interface{ Unwrap() error }:
+		errs = CopyErrors(err.Unwrap())
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package filesystem_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestCopyErrors(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		// Act
+		errs := filesystem.CopyErrors(nil)
+
+		// Assert
+		assert.Nil(t, errs)
+	})
+
+	t.Run("no_copy_error", func(t *testing.T) {
+		// Act
+		errs := filesystem.CopyErrors(errors.Join(errors.New("some error"), fmt.Errorf("wrapped: %w", fs.ErrNotExist)))
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+
+	t.Run("nested_joins", func(t *testing.T) {
+		// Arrange
+		open := &filesystem.CopyError{Op: filesystem.OpOpen, Src: "a", Dest: "b", Err: fs.ErrNotExist}
+		mkdir := &filesystem.CopyError{Op: filesystem.OpMkdir, Src: "c", Dest: "d", Err: fs.ErrPermission}
+		err := errors.Join(open, nil, fmt.Errorf("wrapped: %w", errors.Join(mkdir)))
+
+		// Act
+		errs := filesystem.CopyErrors(err)
+
+		// Assert
+		assert.Equal(t, []*filesystem.CopyError{open, mkdir}, errs)
+	})
+
+	t.Run("copy_dir", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "file.txt"), []byte("hey file"), filesystem.RwRR))
+		require.NoError(t, os.MkdirAll(filepath.Join(srcdir, "sub", "dir"), filesystem.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "sub", "dir", "file.txt"), []byte("hey file"), filesystem.RwRR))
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "sub", "other.txt"), []byte("hey file"), filesystem.RwRR))
+
+		// destination files are directories to make files creation fail
+		destdir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(destdir, "file.txt"), filesystem.RwxRxRxRx))
+		require.NoError(t, os.MkdirAll(filepath.Join(destdir, "sub", "dir", "file.txt"), filesystem.RwxRxRxRx))
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir)
+
+		// Assert
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 2)
+		for _, cerr := range errs {
+			assert.Equal(t, filesystem.OpCreate, cerr.Op)
+		}
+		assert.Equal(t, filepath.Join(destdir, "file.txt"), errs[0].Dest)
+		assert.Equal(t, filepath.Join(srcdir, "sub", "dir", "file.txt"), errs[1].Src)
+		assert.FileExists(t, filepath.Join(destdir, "sub", "other.txt"))
+	})
+}
+
+func TestCopyError_Error(t *testing.T) {
+	cause := errors.New("cause")
+	cases := map[filesystem.Op]string{
+		filesystem.OpOpen:    "failed to read src: cause",
+		filesystem.OpCreate:  "failed to create dest: cause",
+		filesystem.OpCopy:    "failed to copy file src to dest: cause",
+		filesystem.OpChmod:   "failed to update dest permissions: cause",
+		filesystem.OpChown:   "failed to update dest owner: cause",
+		filesystem.OpXattr:   "failed to copy extended attributes of src to dest: cause",
+		filesystem.OpMkdir:   "failed to create folder dest: cause",
+		filesystem.OpReadDir: "failed to read directory src: cause",
+		filesystem.OpBackup:  "failed to backup dest: cause",
+		"link":               "failed to link src to dest: cause",
+	}
+	for op, expected := range cases {
+		t.Run(string(op), func(t *testing.T) {
+			// Arrange
+			err := &filesystem.CopyError{Op: op, Src: "src", Dest: "dest", Err: cause}
+
+			// Act
+			msg := err.Error()
+
+			// Assert
+			assert.Equal(t, expected, msg)
+			assert.ErrorIs(t, err, cause)
+		})
+	}
+}
@@ -2,10 +2,11 @@ package filesystem
 
 import (
 	"errors"
-	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // FSOption represents a function taking an opt client to use filesysem package functions.
@@ -35,14 +36,54 @@ func WithPerm(perm os.FileMode) FSOption {
 	}
 }
 
+// WithDirPerm specifies the permission for created directories in CopyDir (default is 0o755).
+//
+// Permissions are applied explicitly, meaning the process umask doesn't alter them.
+func WithDirPerm(perm os.FileMode) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.dirPerm = perm
+	}
+}
+
+// WithParents specifies that CopyDir must create destdir missing parents (like mkdir -p).
+//
+// Created parents have the same permissions as any other created directory (see WithDirPerm).
+func WithParents() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.parents = true
+	}
+}
+
 type fsOpt struct {
-	fsys FS
-	join Join
-	perm os.FileMode
+	fsys      FS
+	join      Join
+	perm      os.FileMode
+	dirPerm   os.FileMode
+	parents   bool
+	sparse    bool
+	hardLinks bool
+	xattrs    []string
+	chown     Chown
+	owner     OwnerMapping
+	onError   OnError
+
+	transaction bool
+	backupDir   string
+	modes       bool
+
+	filters    []Filter
+	patternErr error
+	maxDepth   int
+	symlinks   SymlinkPolicy
+	postOrder  bool
+	foldCase   bool
+
+	debounce     time.Duration
+	pollInterval time.Duration
 }
 
 func newFSOpt(opts ...FSOption) *fsOpt {
-	o := &fsOpt{}
+	o := &fsOpt{debounce: defaultDebounce, pollInterval: defaultPollInterval}
 	for _, opt := range opts {
 		if opt != nil {
 			opt(o)
@@ -57,70 +98,294 @@ func newFSOpt(opts ...FSOption) *fsOpt {
 	if o.perm == 0 {
 		o.perm = RwRR
 	}
+	if o.dirPerm == 0 {
+		o.dirPerm = RwxRxRxRx
+	}
+	if o.chown == nil {
+		o.chown = defaultChown
+	}
+	if o.debounce < 0 {
+		o.debounce = 0
+	}
+	if o.pollInterval <= 0 {
+		o.pollInterval = defaultPollInterval
+	}
 	return o
 }
 
 // CopyFile copies a provided file from src to dest with a default permission of 0o644. It fails if it's a directory.
+//
+// Any returned error is a *CopyError.
 func CopyFile(src, dest string, opts ...FSOption) error {
-	o := newFSOpt(opts...)
+	return copyFile(newFSOpt(opts...), src, dest)
+}
 
+func copyFile(o *fsOpt, src, dest string) error {
 	// read file from fsys (OperatingFS or specific fsys)
 	sfile, err := o.fsys.Open(src)
 	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", src, err)
+		return &CopyError{Op: OpOpen, Src: src, Dest: dest, Err: err}
 	}
 	defer sfile.Close()
 
 	// create dest in OS filesystem and not given fsys
 	dfile, err := os.Create(dest)
 	if err != nil {
-		return fmt.Errorf("failed to create %s: %w", dest, err)
+		return &CopyError{Op: OpCreate, Src: src, Dest: dest, Err: err}
 	}
 	defer dfile.Close()
 
 	// copy buffer from src to dest
-	if _, err := io.Copy(dfile, sfile); err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
+	if o.sparse {
+		if err := copySparse(dfile, sfile); err != nil {
+			return &CopyError{Op: OpCopy, Src: src, Dest: dest, Err: err}
+		}
+	} else if _, err := io.Copy(dfile, sfile); err != nil {
+		return &CopyError{Op: OpCopy, Src: src, Dest: dest, Err: err}
+	}
+
+	// update dest owner
+	// (before extended attributes and permissions since chown clears setuid, setgid and security.capability)
+	if o.owner != nil {
+		info, _ := sfile.Stat() // without stat, only a forced owner can be applied
+		if err := applyOwner(o, info, dest); err != nil {
+			return &CopyError{Op: OpChown, Src: src, Dest: dest, Err: err}
+		}
+	}
+
+	// copy extended attributes, only possible when src is a file from OS filesystem
+	// (before updating permissions since writing them needs dest to be writable)
+	file, ok := sfile.(*os.File)
+	xattrs := ok && len(o.xattrs) > 0
+	if xattrs {
+		if err := copyXattrs(file.Name(), dest, o.xattrsBeforeChmod); err != nil {
+			return &CopyError{Op: OpXattr, Src: src, Dest: dest, Err: err}
+		}
 	}
 
 	// update dest permissions
 	if err := dfile.Chmod(o.perm); err != nil {
-		return fmt.Errorf("failed to update %s permissions: %w", dest, err)
+		return &CopyError{Op: OpChmod, Src: src, Dest: dest, Err: err}
+	}
+
+	// copy POSIX access ACL after permissions since updating them rewrites the ACL mask
+	if xattrs {
+		if err := copyXattrs(file.Name(), dest, o.xattrsAfterChmod); err != nil {
+			return &CopyError{Op: OpXattr, Src: src, Dest: dest, Err: err}
+		}
 	}
 	return nil
 }
 
 // CopyDir copies recursively a provided directory as destdir. It fails if it's a file.
+//
+// Only files and directories kept by filters are copied (see WithFilter).
+// By default, failures on single files or directories don't stop the copy, they are all joined in the returned error
+// (see WithFailFast and WithOnError to change this behavior).
+// Use CopyErrors to retrieve each of them as a *CopyError.
 func CopyDir(srcdir, destdir string, opts ...FSOption) error {
 	o := newFSOpt(opts...)
+	if o.transaction {
+		tx, err := CopyDirTx(srcdir, destdir, opts...)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
 
-	if err := os.Mkdir(destdir, RwxRxRxRx); err != nil && !os.IsExist(err) {
-		return fmt.Errorf("failed to create folder %s: %w", destdir, err)
+	if o.patternErr != nil {
+		return o.patternErr
 	}
 
-	entries, err := o.fsys.ReadDir(srcdir)
-	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
+	c := &copier{fsOpt: o, links: map[inode]string{}}
+	return c.copyDir(srcdir, destdir, "")
+}
+
+// copier holds the state shared between all directories of a single CopyDir call.
+type copier struct {
+	*fsOpt
+
+	// aborted is true once the error strategy decided to stop the copy.
+	aborted bool
+
+	// links maps already copied files (identified by their inode) to their destination path.
+	links map[inode]string
+
+	// tx records all changes made in destination when the copy is transactional.
+	tx *Transaction
+}
+
+// copyDir copies srcdir into destdir, rel being srcdir path relative to copied root directory.
+func (c *copier) copyDir(srcdir, destdir, rel string) error {
+	var (
+		created []string
+		entries []fs.DirEntry
+	)
+	if err := c.try(func() error {
+		dirs, dirEntries, err := c.prepareDir(srcdir, destdir)
+		created = append(created, dirs...) // a retried operation doesn't create again the same directories
+		entries = dirEntries
+		return err
+	}); err != nil {
+		return errors.Join(err, c.try(func() error { return c.chmodDirs(srcdir, created) }))
 	}
 
 	errs := make([]error, 0, len(entries))
 	for _, entry := range entries {
-		src := o.join(srcdir, entry.Name())
+		if c.aborted {
+			break
+		}
+
+		path := entry.Name()
+		if rel != "" {
+			path = c.join(rel, entry.Name())
+		}
+		if !c.keep(path, entry) {
+			continue
+		}
+
+		src := c.join(srcdir, entry.Name())
 		dest := filepath.Join(destdir, entry.Name())
 
 		// handle directories
 		if entry.IsDir() {
-			errs = append(errs, CopyDir(src, dest, opts...))
+			errs = append(errs, c.copyDir(src, dest, path))
 			continue
 		}
 
 		// handle files
-		errs = append(errs, CopyFile(src, dest, opts...))
+		errs = append(errs, c.try(func() error { return c.copyEntry(entry, src, dest) }))
+	}
+	errs = append(errs, c.try(func() error { return c.chmodDirs(srcdir, created) }))
+
+	// copy POSIX access ACL after permissions since updating them rewrites the ACL mask
+	if c.fsys == OS() && len(c.xattrs) > 0 {
+		errs = append(errs, c.try(func() error {
+			if err := copyXattrs(srcdir, destdir, c.xattrsAfterChmod); err != nil {
+				return &CopyError{Op: OpXattr, Src: srcdir, Dest: destdir, Err: err}
+			}
+			return nil
+		}))
 	}
 	return errors.Join(errs...)
 }
 
+// prepareDir creates destdir (if it doesn't exist) and returns the created directories and srcdir entries.
+func (c *copier) prepareDir(srcdir, destdir string) ([]string, []fs.DirEntry, error) {
+	created, err := c.mkdir(srcdir, destdir)
+	if err != nil {
+		return created, nil, err
+	}
+
+	// update destdir owner
+	if c.owner != nil {
+		info, _ := fs.Stat(c.fsys, srcdir) // without stat, only a forced owner can be applied
+		if err := applyOwner(c.fsOpt, info, destdir); err != nil {
+			return created, nil, &CopyError{Op: OpChown, Src: srcdir, Dest: destdir, Err: err}
+		}
+	}
+
+	// copy destdir extended attributes (e.g. POSIX default ACL), only possible when srcdir is from OS filesystem
+	if c.fsys == OS() && len(c.xattrs) > 0 {
+		if err := copyXattrs(srcdir, destdir, c.xattrsBeforeChmod); err != nil {
+			return created, nil, &CopyError{Op: OpXattr, Src: srcdir, Dest: destdir, Err: err}
+		}
+	}
+
+	entries, err := c.fsys.ReadDir(srcdir)
+	if err != nil {
+		return created, nil, &CopyError{Op: OpReadDir, Src: srcdir, Dest: destdir, Err: err}
+	}
+	return created, entries, nil
+}
+
+// mkdir creates destdir (and its missing parents when asked) and returns the created directories.
+//
+// Created directories stay writable by their owner until their content is copied,
+// expected permissions are applied afterwards by chmodDirs (like cp -r).
+func (c *copier) mkdir(srcdir, destdir string) ([]string, error) {
+	dirs := []string{destdir}
+	if c.parents {
+		for dir := filepath.Dir(destdir); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if _, err := os.Lstat(dir); !errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	var created []string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+
+		if c.tx != nil {
+			if err := c.tx.record(dir); err != nil {
+				return created, &CopyError{Op: OpBackup, Src: srcdir, Dest: dir, Err: err}
+			}
+		}
+
+		if err := os.Mkdir(dir, c.dirPerm|0o700); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return created, &CopyError{Op: OpMkdir, Src: srcdir, Dest: dir, Err: err}
+		}
+		created = append(created, dir)
+	}
+	return created, nil
+}
+
+// chmodDirs applies expected directory permissions on created directories, deepest ones first.
+//
+// Permissions are applied explicitly to avoid any alteration by the process umask.
+func (c *copier) chmodDirs(srcdir string, created []string) error {
+	errs := make([]error, 0, len(created))
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := os.Chmod(created[i], c.dirPerm); err != nil {
+			errs = append(errs, &CopyError{Op: OpChmod, Src: srcdir, Dest: created[i], Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// copyEntry copies a single file entry from src to dest,
+// recreating a hard link instead when the entry was already copied and links preservation is enabled.
+func (c *copier) copyEntry(entry fs.DirEntry, src, dest string) error {
+	if c.tx != nil {
+		if err := c.tx.record(dest); err != nil {
+			return &CopyError{Op: OpBackup, Src: src, Dest: dest, Err: err}
+		}
+	}
+
+	if !c.hardLinks {
+		return copyFile(c.fsOpt, src, dest)
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		return copyFile(c.fsOpt, src, dest)
+	}
+	id, ok := fileID(info)
+	if !ok {
+		return copyFile(c.fsOpt, src, dest)
+	}
+
+	if first, ok := c.links[id]; ok && link(first, dest) == nil {
+		return nil
+	}
+	if err := copyFile(c.fsOpt, src, dest); err != nil {
+		return err
+	}
+	if _, ok := c.links[id]; !ok {
+		c.links[id] = dest
+	}
+	return nil
+}
+
 // Exists returns a boolean indicating whether the provided input src exists or not.
+//
+// It opens src and returns false on any error, meaning an unreadable file (permission denied, I/O error, etc.)
+// is reported as not existing. Use CheckExists to distinguish those errors.
 func Exists(src string, opts ...FSOption) bool {
 	o := newFSOpt(opts...)
 
@@ -132,3 +397,48 @@ func Exists(src string, opts ...FSOption) bool {
 	_ = file.Close()
 	return true
 }
+
+// CheckExists returns a boolean indicating whether the provided input src exists or not.
+//
+// Unlike Exists, it doesn't open src (it uses fs.StatFS when the FS implements it)
+// and returns any error other than fs.ErrNotExist.
+func CheckExists(src string, opts ...FSOption) (bool, error) {
+	o := newFSOpt(opts...)
+
+	_, err := fs.Stat(o.fsys, src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsFile returns a boolean indicating whether the provided input src exists and is a regular file.
+//
+// Symbolic links are followed.
+func IsFile(src string, opts ...FSOption) bool {
+	o := newFSOpt(opts...)
+
+	info, err := fs.Stat(o.fsys, src)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// IsDir returns a boolean indicating whether the provided input src exists and is a directory.
+//
+// Symbolic links are followed.
+func IsDir(src string, opts ...FSOption) bool {
+	o := newFSOpt(opts...)
+
+	info, err := fs.Stat(o.fsys, src)
+	return err == nil && info.IsDir()
+}
+
+// IsSymlink returns a boolean indicating whether the provided input src exists and is a symbolic link.
+func IsSymlink(src string, opts ...FSOption) bool {
+	o := newFSOpt(opts...)
+
+	info, err := lstat(o.fsys, src)
+	return err == nil && info.Mode()&fs.ModeSymlink != 0
+}
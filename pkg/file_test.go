@@ -1,9 +1,13 @@
 package filesystem_test
 
 import (
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +33,10 @@ func TestCopyFile(t *testing.T) {
 
 		// Assert
 		assert.ErrorContains(t, err, "failed to read")
+		var cerr *filesystem.CopyError
+		require.ErrorAs(t, err, &cerr)
+		assert.Equal(t, filesystem.OpOpen, cerr.Op)
+		assert.Equal(t, src, cerr.Src)
 		assert.NoFileExists(t, dest)
 	})
 
@@ -41,6 +49,10 @@ func TestCopyFile(t *testing.T) {
 
 		// Assert
 		assert.ErrorContains(t, err, "failed to create")
+		var cerr *filesystem.CopyError
+		require.ErrorAs(t, err, &cerr)
+		assert.Equal(t, filesystem.OpCreate, cerr.Op)
+		assert.Equal(t, dest, cerr.Dest)
 		assert.NoFileExists(t, dest)
 	})
 
@@ -76,6 +88,9 @@ func TestCopyDir(t *testing.T) {
 
 		// Assert
 		assert.ErrorContains(t, err, "failed to read directory")
+		var cerr *filesystem.CopyError
+		require.ErrorAs(t, err, &cerr)
+		assert.Equal(t, filesystem.OpReadDir, cerr.Op)
 	})
 
 	t.Run("error_no_destdir", func(t *testing.T) {
@@ -93,6 +108,92 @@ func TestCopyDir(t *testing.T) {
 
 		// Assert
 		assert.ErrorContains(t, err, "failed to create folder")
+		var cerr *filesystem.CopyError
+		require.ErrorAs(t, err, &cerr)
+		assert.Equal(t, filesystem.OpMkdir, cerr.Op)
+		assert.Equal(t, destdir, cerr.Dest)
+	})
+
+	t.Run("error_injected_readdir", func(t *testing.T) {
+		// Arrange
+		fsys := tests.NewFaultFS(fstest.MapFS{
+			"a/file.txt": &fstest.MapFile{Data: []byte("hey file")},
+			"b/file.txt": &fstest.MapFile{Data: []byte("hey file")},
+		}, tests.Inject(tests.FaultReadDir, "a"))
+		destdir := filepath.Join(t.TempDir(), "dir")
+
+		// Act
+		err := filesystem.CopyDir(".", destdir, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join))
+
+		// Assert
+		assert.ErrorIs(t, err, tests.ErrInjected)
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, filesystem.OpReadDir, errs[0].Op)
+		assert.Equal(t, "a", errs[0].Src)
+		assert.FileExists(t, filepath.Join(destdir, "b", "file.txt"))
+	})
+
+	t.Run("error_injected_nth_open", func(t *testing.T) {
+		// Arrange
+		fsys := tests.NewFaultFS(fstest.MapFS{
+			"file1.txt": &fstest.MapFile{Data: []byte("hey file")},
+			"file2.txt": &fstest.MapFile{Data: []byte("hey file")},
+			"file3.txt": &fstest.MapFile{Data: []byte("hey file")},
+		}, tests.Inject(tests.FaultOpen, "**", tests.WithNth(2), tests.WithError(fs.ErrPermission)))
+		destdir := filepath.Join(t.TempDir(), "dir")
+
+		// Act
+		err := filesystem.CopyDir(".", destdir, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join))
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrPermission)
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, filesystem.OpOpen, errs[0].Op)
+		assert.Equal(t, "file2.txt", errs[0].Src)
+		assert.FileExists(t, filepath.Join(destdir, "file1.txt"))
+		assert.NoFileExists(t, filepath.Join(destdir, "file2.txt"))
+		assert.FileExists(t, filepath.Join(destdir, "file3.txt"))
+	})
+
+	t.Run("error_injected_read", func(t *testing.T) {
+		// Arrange
+		fsys := tests.NewFaultFS(fstest.MapFS{
+			"sub/file.txt": &fstest.MapFile{Data: []byte("hey file")},
+			"file.txt":     &fstest.MapFile{Data: []byte("hey file")},
+		}, tests.Inject(tests.FaultRead, "sub/**"))
+		destdir := filepath.Join(t.TempDir(), "dir")
+
+		// Act
+		err := filesystem.CopyDir(".", destdir, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join))
+
+		// Assert
+		assert.ErrorIs(t, err, tests.ErrInjected)
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, filesystem.OpCopy, errs[0].Op)
+		assert.Equal(t, "sub/file.txt", errs[0].Src)
+		assert.FileExists(t, filepath.Join(destdir, "file.txt"))
+	})
+
+	t.Run("success_short_reads", func(t *testing.T) {
+		// Arrange
+		srcdir := tests.TxtarDir(t, `
+-- file.txt --
+hey file
+-- sub/file.txt --
+hey sub file
+`)
+		fsys := tests.NewFaultFS(filesystem.OS(), tests.Inject(tests.FaultRead, "**", tests.WithShortRead(1)))
+		destdir := filepath.Join(t.TempDir(), "dir")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithFS(fsys))
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, srcdir, destdir)
 	})
 
 	t.Run("success", func(t *testing.T) {
@@ -122,6 +223,105 @@ func TestCopyDir(t *testing.T) {
 		assert.NoError(t, err)
 		tests.AssertEqualDir(t, srcdir, destdir)
 	})
+	t.Run("success_filter", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"file.txt":         &fstest.MapFile{Data: []byte("hey file")},
+			"file.tmp":         &fstest.MapFile{Data: []byte("hey file")},
+			"sub/file.txt":     &fstest.MapFile{Data: []byte("hey file")},
+			"ignored/file.txt": &fstest.MapFile{Data: []byte("hey file")},
+		}
+		filter := func(path string, _ fs.DirEntry) bool {
+			return path != "ignored" && filepath.Ext(path) != ".tmp"
+		}
+		destdir := filepath.Join(t.TempDir(), "dir")
+
+		// Act
+		err := filesystem.CopyDir(".", destdir, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join), filesystem.WithFilter(filter))
+
+		// Assert
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(destdir, "file.txt"))
+		assert.FileExists(t, filepath.Join(destdir, "sub", "file.txt"))
+		assert.NoFileExists(t, filepath.Join(destdir, "file.tmp"))
+		assert.NoDirExists(t, filepath.Join(destdir, "ignored"))
+	})
+
+	t.Run("success_parents_and_dir_perm", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(srcdir, "sub"), filesystem.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "sub", "file.txt"), []byte("hey file"), filesystem.RwRR))
+
+		parent := filepath.Join(t.TempDir(), "missing", "parent")
+		destdir := filepath.Join(parent, "dir")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithParents(), filesystem.WithDirPerm(0o777))
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, srcdir, destdir)
+		if runtime.GOOS == "windows" {
+			return // permissions aren't relevant on windows
+		}
+		for _, dir := range []string{filepath.Dir(parent), parent, destdir, filepath.Join(destdir, "sub")} {
+			info, err := os.Stat(dir)
+			require.NoError(t, err)
+			assert.Equal(t, fs.FileMode(0o777), info.Mode().Perm(), dir) // umask must not be applied
+		}
+	})
+
+	t.Run("success_read_only_dir_perm", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(srcdir, "sub"), filesystem.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "sub", "file.txt"), []byte("hey file"), filesystem.RwRR))
+		destdir := filepath.Join(t.TempDir(), "dir")
+		t.Cleanup(func() {
+			// read-only directories can't be removed by testing package cleanup when not running as root
+			_ = filepath.WalkDir(destdir, func(path string, entry fs.DirEntry, _ error) error {
+				if entry != nil && entry.IsDir() {
+					_ = os.Chmod(path, filesystem.RwxRxRxRx)
+				}
+				return nil
+			})
+		})
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithDirPerm(0o555))
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "sub", "file.txt"), filepath.Join(destdir, "sub", "file.txt"))
+		if runtime.GOOS == "windows" {
+			return // permissions aren't relevant on windows
+		}
+		for _, dir := range []string{destdir, filepath.Join(destdir, "sub")} {
+			info, err := os.Stat(dir)
+			require.NoError(t, err)
+			assert.Equal(t, fs.FileMode(0o555), info.Mode().Perm(), dir)
+		}
+	})
+
+	t.Run("success_private_dir_perm", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "file.txt"), []byte("hey file"), filesystem.RwRR))
+		destdir := filepath.Join(t.TempDir(), "dir")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithDirPerm(0o700))
+
+		// Assert
+		require.NoError(t, err)
+		if runtime.GOOS == "windows" {
+			return // permissions aren't relevant on windows
+		}
+		info, err := os.Stat(destdir)
+		require.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0o700), info.Mode().Perm())
+	})
 }
 
 func TestExists(t *testing.T) {
@@ -151,3 +351,93 @@ func TestExists(t *testing.T) {
 		assert.True(t, exists)
 	})
 }
+
+func TestCheckExists(t *testing.T) {
+	t.Run("false_not_exists", func(t *testing.T) {
+		// Arrange
+		invalid := filepath.Join(t.TempDir(), "invalid")
+
+		// Act
+		exists, err := filesystem.CheckExists(invalid)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("error_invalid", func(t *testing.T) {
+		// Arrange
+		src := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(src, []byte("hey file"), filesystem.RwRR))
+
+		// Act
+		exists, err := filesystem.CheckExists(filepath.Join(src, "sub")) // a file can't have children
+
+		// Assert
+		if runtime.GOOS == "windows" {
+			assert.NoError(t, err) // windows reports a not found error
+		} else {
+			assert.Error(t, err)
+		}
+		assert.False(t, exists)
+	})
+
+	t.Run("true_exists", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{"dir/file.txt": &fstest.MapFile{Data: []byte("hey file")}}
+
+		// Act
+		exists, err := filesystem.CheckExists("dir/file.txt", filesystem.WithFS(fsys))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func TestIsFileIsDirIsSymlink(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("hey file"), filesystem.RwRR))
+	dir := filepath.Join(tmp, "dir")
+	require.NoError(t, os.Mkdir(dir, filesystem.RwxRxRxRx))
+	invalid := filepath.Join(tmp, "invalid")
+
+	t.Run("file", func(t *testing.T) {
+		assert.True(t, filesystem.IsFile(file))
+		assert.False(t, filesystem.IsDir(file))
+		assert.False(t, filesystem.IsSymlink(file))
+	})
+
+	t.Run("dir", func(t *testing.T) {
+		assert.False(t, filesystem.IsFile(dir))
+		assert.True(t, filesystem.IsDir(dir))
+		assert.False(t, filesystem.IsSymlink(dir))
+	})
+
+	t.Run("not_exists", func(t *testing.T) {
+		assert.False(t, filesystem.IsFile(invalid))
+		assert.False(t, filesystem.IsDir(invalid))
+		assert.False(t, filesystem.IsSymlink(invalid))
+	})
+
+	t.Run("symlink", func(t *testing.T) {
+		link := filepath.Join(t.TempDir(), "link")
+		if err := os.Symlink(dir, link); err != nil {
+			t.Skip("symbolic links not supported:", err)
+		}
+		assert.False(t, filesystem.IsFile(link))
+		assert.True(t, filesystem.IsDir(link))
+		assert.True(t, filesystem.IsSymlink(link))
+	})
+
+	t.Run("symlink_other_fs", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"file.txt": &fstest.MapFile{Data: []byte("hey file")},
+			"link":     &fstest.MapFile{Data: []byte("file.txt"), Mode: fs.ModeSymlink},
+		}
+		assert.True(t, filesystem.IsSymlink("link", filesystem.WithFS(fsys)))
+		assert.False(t, filesystem.IsSymlink("file.txt", filesystem.WithFS(fsys)))
+		assert.False(t, filesystem.IsSymlink("invalid", filesystem.WithFS(fsys)))
+	})
+}
@@ -2,6 +2,7 @@ package filesystem
 
 import (
 	"io/fs"
+	"path"
 )
 
 // FS represents a filesystem with required minimal functions like Open, ReadDir and ReadFile.
@@ -10,3 +11,35 @@ type FS interface {
 	fs.ReadDirFS
 	fs.ReadFileFS
 }
+
+// readLinkFS represents a filesystem supporting symbolic links (same as fs.ReadLinkFS available since go1.25).
+type readLinkFS interface {
+	fs.FS
+
+	// ReadLink returns the destination of the named symbolic link.
+	ReadLink(name string) (string, error)
+
+	// Lstat returns a FileInfo describing the named file without following symbolic links.
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// lstat returns a FileInfo describing the named file without following symbolic links.
+//
+// When fsys doesn't implement Lstat, name is looked up in its parent directory entries.
+func lstat(fsys FS, name string) (fs.FileInfo, error) {
+	if fsys, ok := fsys.(readLinkFS); ok {
+		return fsys.Lstat(name)
+	}
+
+	entries, err := fsys.ReadDir(path.Dir(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: err}
+	}
+	base := path.Base(name)
+	for _, entry := range entries {
+		if entry.Name() == base {
+			return entry.Info()
+		}
+	}
+	return nil, &fs.PathError{Op: "lstat", Path: name, Err: fs.ErrNotExist}
+}
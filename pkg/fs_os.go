@@ -38,3 +38,23 @@ func (*osFS) ReadDir(name string) ([]fs.DirEntry, error) {
 func (*osFS) ReadFile(name string) ([]byte, error) {
 	return os.ReadFile(name)
 }
+
+// Stat returns a FileInfo describing the named file.
+// If there is an error, it will be of type *PathError.
+func (*osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Lstat returns a FileInfo describing the named file.
+// If the file is a symbolic link, the returned FileInfo
+// describes the symbolic link. Lstat makes no attempt to follow the link.
+// If there is an error, it will be of type *PathError.
+func (*osFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+// ReadLink returns the destination of the named symbolic link.
+// If there is an error, it will be of type *PathError.
+func (*osFS) ReadLink(name string) (string, error) {
+	return os.Readlink(name)
+}
@@ -1,6 +1,7 @@
 package filesystem_test
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -48,4 +49,37 @@ func TestOperatingFS(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "hey !", string(bytes))
 	})
+
+	t.Run("success_stat", func(t *testing.T) {
+		// Act
+		info, err := fsys.(fs.StatFS).Stat(name)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "hey.txt", info.Name())
+		assert.True(t, info.Mode().IsRegular())
+	})
+
+	t.Run("success_lstat_read_link", func(t *testing.T) {
+		// Arrange
+		link := filepath.Join(t.TempDir(), "link.txt")
+		if err := os.Symlink(name, link); err != nil {
+			t.Skip("symbolic links not supported:", err)
+		}
+		lfsys, ok := fsys.(interface {
+			Lstat(name string) (fs.FileInfo, error)
+			ReadLink(name string) (string, error)
+		})
+		require.True(t, ok)
+
+		// Act
+		info, err := lfsys.Lstat(link)
+		require.NoError(t, err)
+		target, err := lfsys.ReadLink(link)
+		require.NoError(t, err)
+
+		// Assert
+		assert.NotZero(t, info.Mode()&fs.ModeSymlink)
+		assert.Equal(t, name, target)
+	})
 }
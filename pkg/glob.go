@@ -0,0 +1,316 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WithCaseInsensitive specifies that Glob, WithInclude and WithExclude patterns must match regardless of case.
+func WithCaseInsensitive() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.foldCase = true
+	}
+}
+
+// WithInclude specifies patterns (see Match for syntax) of files to keep in Walk, CopyDir and Watch.
+//
+// A file is kept when it matches at least one pattern,
+// a directory is kept when it matches at least one pattern or when it may contain a matching file.
+// Malformed patterns make filtered functions (e.g. Walk or CopyDir) fail with path.ErrBadPattern.
+func WithInclude(patterns ...string) FSOption {
+	return func(fsOpt *fsOpt) {
+		matchers := fsOpt.newMatchers(patterns)
+		fsOpt.filters = append(fsOpt.filters, func(name string, entry fs.DirEntry) bool {
+			m := matchers[fsOpt.foldCase]
+			if m == nil {
+				return false
+			}
+			segments := split(filepath.ToSlash(name))
+			return m.match(segments) || (entry.IsDir() && m.matchPrefix(segments))
+		})
+	}
+}
+
+// WithExclude specifies patterns (see Match for syntax) of files and directories to ignore in Walk, CopyDir and Watch.
+//
+// Malformed patterns make filtered functions (e.g. Walk or CopyDir) fail with path.ErrBadPattern.
+func WithExclude(patterns ...string) FSOption {
+	return func(fsOpt *fsOpt) {
+		matchers := fsOpt.newMatchers(patterns)
+		fsOpt.filters = append(fsOpt.filters, func(name string, _ fs.DirEntry) bool {
+			m := matchers[fsOpt.foldCase]
+			return m == nil || !m.match(split(filepath.ToSlash(name)))
+		})
+	}
+}
+
+// newMatchers compiles patterns both case sensitive (false key) and case insensitive (true key),
+// since WithCaseInsensitive may be given after WithInclude or WithExclude.
+//
+// Both are nil when patterns are malformed, the error being kept to be returned by filtered functions.
+func (o *fsOpt) newMatchers(patterns []string) map[bool]*matcher {
+	sensitive, err := newMatcher(patterns, false)
+	if err != nil {
+		o.patternErr = errors.Join(o.patternErr, fmt.Errorf("failed to compile patterns %q: %w", patterns, err))
+		return map[bool]*matcher{}
+	}
+	insensitive, _ := newMatcher(patterns, true)
+	return map[bool]*matcher{false: sensitive, true: insensitive}
+}
+
+// Match reports whether name (a slash separated path) matches the pattern.
+//
+// In addition to path.Match syntax ('*', '?', '[a-z]', '[^a-z]' and '\\' escaping), it supports:
+//   - '**' as a complete path segment to match zero or more directories (e.g. "a/**/*.go"),
+//   - '[!a-z]' as an alternative to '[^a-z]' for negated character classes,
+//   - '{a,b}' for brace expansion (e.g. "*.{yml,yaml}"), braces can be nested.
+//
+// The only possible returned error is path.ErrBadPattern, when pattern is malformed.
+func Match(pattern, name string) (bool, error) {
+	m, err := newMatcher([]string{pattern}, false)
+	if err != nil {
+		return false, err
+	}
+	return m.match(split(name)), nil
+}
+
+// Glob returns all files and directories inside root (root excluded) matching pattern (see Match for syntax),
+// as sorted paths relative to root.
+//
+// Directories which can't contain any matching file aren't read.
+// Options like WithFS, WithJoin, WithFilter and WithCaseInsensitive are taken into account.
+//
+// The only possible returned error from pattern is path.ErrBadPattern, when pattern is malformed.
+func Glob(root, pattern string, opts ...FSOption) ([]string, error) {
+	o := newFSOpt(opts...)
+	m, err := newMatcher([]string{pattern}, o.foldCase)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	err = Walk(root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		segments := split(filepath.ToSlash(name))
+		if m.match(segments) {
+			matches = append(matches, name)
+		}
+		if entry.IsDir() && !m.matchPrefix(segments) {
+			return fs.SkipDir
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// matcher represents a set of compiled patterns, a path matches when it matches any of them.
+type matcher struct {
+	// patterns are brace expanded patterns split by path segments.
+	patterns [][]string
+	fold     bool
+}
+
+// newMatcher compiles given patterns and verifies that they're well formed.
+func newMatcher(patterns []string, fold bool) (*matcher, error) {
+	m := &matcher{fold: fold}
+	for _, pattern := range patterns {
+		expanded, err := expandBraces(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pattern := range expanded {
+			if fold {
+				pattern = strings.ToLower(pattern)
+			}
+			segments := split(pattern)
+			for i, segment := range segments {
+				if segment == "**" {
+					continue
+				}
+				segment = negateClasses(segment)
+				if _, err := path.Match(segment, ""); err != nil {
+					return nil, err
+				}
+				segments[i] = segment
+			}
+			m.patterns = append(m.patterns, segments)
+		}
+	}
+	return m, nil
+}
+
+// negateClasses rewrites character classes negated with '[!' into '[^' (the only form supported by path.Match).
+//
+// Escaped brackets and brackets inside a character class don't start a class and are left untouched.
+func negateClasses(segment string) string {
+	var b strings.Builder
+	class := false
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		b.WriteByte(c)
+		switch {
+		case c == '\\' && i+1 < len(segment):
+			i++
+			b.WriteByte(segment[i])
+		case c == '[' && !class:
+			class = true
+			if i+1 < len(segment) && segment[i+1] == '!' {
+				b.WriteByte('^')
+				i++
+			}
+		case c == ']' && class:
+			class = false
+		}
+	}
+	return b.String()
+}
+
+// match returns true if name segments match one of matcher patterns.
+func (m *matcher) match(name []string) bool {
+	name = m.normalize(name)
+	for _, pattern := range m.patterns {
+		if matchSegments(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrefix returns true if a path inside dir (given as segments) could match one of matcher patterns.
+func (m *matcher) matchPrefix(dir []string) bool {
+	dir = m.normalize(dir)
+	for _, pattern := range m.patterns {
+		if matchPrefix(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize returns name segments in lower case when matcher is case insensitive.
+func (m *matcher) normalize(name []string) []string {
+	if !m.fold {
+		return name
+	}
+	lower := make([]string, 0, len(name))
+	for _, segment := range name {
+		lower = append(lower, strings.ToLower(segment))
+	}
+	return lower
+}
+
+// matchSegments returns true if all name segments match pattern segments.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		return matchSegments(pattern[1:], name) || (len(name) > 0 && matchSegments(pattern, name[1:]))
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0]) // patterns are validated in newMatcher
+	return ok && matchSegments(pattern[1:], name[1:])
+}
+
+// matchPrefix returns true if a path starting with dir segments could match pattern segments.
+func matchPrefix(pattern, dir []string) bool {
+	if len(dir) == 0 {
+		return len(pattern) > 0
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+	ok, _ := path.Match(pattern[0], dir[0]) // patterns are validated in newMatcher
+	return ok && matchPrefix(pattern[1:], dir[1:])
+}
+
+// expandBraces returns all patterns described by pattern braces, e.g. "a{b,c{d,e}}" gives "ab", "acd" and "ace".
+func expandBraces(pattern string) ([]string, error) {
+	start := -1
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++ // escaped character
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				return nil, path.ErrBadPattern
+			}
+			depth--
+			if depth > 0 {
+				continue
+			}
+
+			prefix, suffix := pattern[:start], pattern[i+1:]
+			var expanded []string
+			for _, alternative := range splitAlternatives(pattern[start+1 : i]) {
+				sub, err := expandBraces(prefix + alternative + suffix)
+				if err != nil {
+					return nil, err
+				}
+				expanded = append(expanded, sub...)
+			}
+			return expanded, nil
+		}
+	}
+	if depth > 0 {
+		return nil, path.ErrBadPattern
+	}
+	return []string{pattern}, nil
+}
+
+// splitAlternatives splits braces content on top level commas.
+func splitAlternatives(content string) []string {
+	var alternatives []string
+	depth := 0
+	last := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++ // escaped character
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, content[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(alternatives, content[last:])
+}
+
+// split returns the segments of a slash separated path.
+func split(name string) []string {
+	name = strings.Trim(name, "/")
+	if name == "" || name == "." {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
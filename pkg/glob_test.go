@@ -0,0 +1,236 @@
+package filesystem_test
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{pattern: "*.go", name: "main.go", match: true},
+		{pattern: "*.go", name: "pkg/main.go", match: false},
+		{pattern: "**/*.go", name: "main.go", match: true},
+		{pattern: "**/*.go", name: "pkg/sub/main.go", match: true},
+		{pattern: "pkg/**", name: "pkg", match: true},
+		{pattern: "pkg/**", name: "pkg/sub/main.go", match: true},
+		{pattern: "pkg/**/main.go", name: "pkg/main.go", match: true},
+		{pattern: "pkg/**/main.go", name: "other/main.go", match: false},
+		{pattern: "file[0-9].txt", name: "file1.txt", match: true},
+		{pattern: "file[!0-9].txt", name: "file1.txt", match: false},
+		{pattern: "file[^0-9].txt", name: "filea.txt", match: true},
+		{pattern: "file\\[!0-9].txt", name: "file[!0-9].txt", match: true},
+		{pattern: "file\\[!0-9].txt", name: "filea.txt", match: false},
+		{pattern: "file[[!].txt", name: "file!.txt", match: true},
+		{pattern: "file?.txt", name: "file12.txt", match: false},
+		{pattern: "*.{yml,yaml}", name: "config.yaml", match: true},
+		{pattern: "*.{yml,yaml}", name: "config.json", match: false},
+		{pattern: "{a,b{c,d}}/*.txt", name: "bd/file.txt", match: true},
+		{pattern: "{a,b{c,d}}/*.txt", name: "b/file.txt", match: false},
+		{pattern: "\\{a,b\\}", name: "{a,b}", match: true},
+		{pattern: "*.GO", name: "main.go", match: false},
+	}
+	for _, c := range cases {
+		t.Run(c.pattern+"_"+c.name, func(t *testing.T) {
+			// Act
+			match, err := filesystem.Match(c.pattern, c.name)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, c.match, match)
+		})
+	}
+
+	t.Run("error_bad_pattern", func(t *testing.T) {
+		for _, pattern := range []string{"[a-", "{a,b", "a}", "a/[/b"} {
+			// Act
+			_, err := filesystem.Match(pattern, "a")
+
+			// Assert
+			assert.ErrorIs(t, err, path.ErrBadPattern, pattern)
+		}
+	})
+}
+
+// countFS counts all ReadDir calls made on an underlying filesystem.FS.
+type countFS struct {
+	filesystem.FS
+
+	reads []string
+}
+
+func (c *countFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	c.reads = append(c.reads, name)
+	return c.FS.ReadDir(name)
+}
+
+func TestGlob(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"README.md":            &fstest.MapFile{Data: []byte("readme")},
+		"main.go":              &fstest.MapFile{Data: []byte("main")},
+		"pkg/file.go":          &fstest.MapFile{Data: []byte("file")},
+		"pkg/file_test.go":     &fstest.MapFile{Data: []byte("test")},
+		"pkg/sub/Other.GO":     &fstest.MapFile{Data: []byte("other")},
+		"testdata/a/b/c.json":  &fstest.MapFile{Data: []byte("{}")},
+		"testdata/a/b/c.yaml":  &fstest.MapFile{Data: []byte("c:")},
+		"vendor/mod/vendor.go": &fstest.MapFile{Data: []byte("vendor")},
+	}
+
+	t.Run("error_bad_pattern", func(t *testing.T) {
+		// Act
+		_, err := filesystem.Glob(".", "[", filesystem.WithFS(mapfs))
+
+		// Assert
+		assert.ErrorIs(t, err, path.ErrBadPattern)
+	})
+
+	t.Run("error_root", func(t *testing.T) {
+		// Act
+		_, err := filesystem.Glob("invalid", "*", filesystem.WithFS(mapfs))
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("success_doublestar", func(t *testing.T) {
+		// Act
+		matches, err := filesystem.Glob(".", "**/*.go", filesystem.WithFS(mapfs), filesystem.WithJoin(path.Join))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main.go", "pkg/file.go", "pkg/file_test.go", "vendor/mod/vendor.go"}, matches)
+	})
+
+	t.Run("success_case_insensitive", func(t *testing.T) {
+		// Act
+		matches, err := filesystem.Glob(".", "pkg/**/*.go", filesystem.WithFS(mapfs), filesystem.WithJoin(path.Join), filesystem.WithCaseInsensitive())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pkg/file.go", "pkg/file_test.go", "pkg/sub/Other.GO"}, matches)
+	})
+
+	t.Run("success_braces_and_dirs", func(t *testing.T) {
+		// Act
+		matches, err := filesystem.Glob(".", "{testdata/*/b,testdata/**/*.{yml,yaml}}", filesystem.WithFS(mapfs), filesystem.WithJoin(path.Join))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"testdata/a/b", "testdata/a/b/c.yaml"}, matches)
+	})
+
+	t.Run("success_stop_descent", func(t *testing.T) {
+		// Arrange
+		fsys := &countFS{FS: mapfs}
+
+		// Act
+		matches, err := filesystem.Glob(".", "pkg/*.go", filesystem.WithFS(fsys), filesystem.WithJoin(path.Join))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pkg/file.go", "pkg/file_test.go"}, matches)
+		assert.Equal(t, []string{".", "pkg"}, fsys.reads)
+	})
+
+	t.Run("success_with_exclude", func(t *testing.T) {
+		// Act
+		matches, err := filesystem.Glob(".", "**/*.go", filesystem.WithFS(mapfs), filesystem.WithJoin(path.Join), filesystem.WithExclude("vendor", "**/*_test.go"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main.go", "pkg/file.go"}, matches)
+	})
+
+	t.Run("success_os", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		require.NoError(t, filesystem.CopyDir(".", root, filesystem.WithFS(mapfs), filesystem.WithJoin(path.Join)))
+
+		// Act
+		matches, err := filesystem.Glob(root, "**/file*.go")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join("pkg", "file.go"), filepath.Join("pkg", "file_test.go")}, matches)
+	})
+}
+
+func TestWithIncludeExclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":          &fstest.MapFile{Data: []byte("main")},
+		"docs/README.MD":   &fstest.MapFile{Data: []byte("readme")},
+		"pkg/file.go":      &fstest.MapFile{Data: []byte("file")},
+		"pkg/file_test.go": &fstest.MapFile{Data: []byte("test")},
+		"pkg/sub/notes.md": &fstest.MapFile{Data: []byte("notes")},
+	}
+
+	// walk returns all visited files (directories are ignored)
+	walk := func(t *testing.T, opts ...filesystem.FSOption) []string {
+		t.Helper()
+		var files []string
+		err := filesystem.Walk(".", func(path string, entry fs.DirEntry, err error) error {
+			require.NoError(t, err)
+			if !entry.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		}, append(opts, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join))...)
+		require.NoError(t, err)
+		return files
+	}
+
+	t.Run("include", func(t *testing.T) {
+		// Act
+		files := walk(t, filesystem.WithInclude("pkg/**/*.go", "*.md"))
+
+		// Assert
+		assert.Equal(t, []string{"pkg/file.go", "pkg/file_test.go"}, files)
+	})
+
+	t.Run("include_case_insensitive", func(t *testing.T) {
+		// Act
+		files := walk(t, filesystem.WithInclude("**/*.md"), filesystem.WithCaseInsensitive())
+
+		// Assert
+		assert.Equal(t, []string{"docs/README.MD", "pkg/sub/notes.md"}, files)
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		// Act
+		files := walk(t, filesystem.WithExclude("**/*_test.go", "pkg/sub"))
+
+		// Assert
+		assert.Equal(t, []string{"docs/README.MD", "main.go", "pkg/file.go"}, files)
+	})
+
+	t.Run("error_malformed", func(t *testing.T) {
+		for _, opt := range []filesystem.FSOption{filesystem.WithInclude("["), filesystem.WithExclude("*.go", "[")} {
+			opts := []filesystem.FSOption{opt, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join)}
+
+			// Act
+			walkErr := filesystem.Walk(".", func(string, fs.DirEntry, error) error { return nil }, opts...)
+			copyErr := filesystem.CopyDir(".", filepath.Join(t.TempDir(), "dest"), opts...)
+			_, txErr := filesystem.CopyDirTx(".", filepath.Join(t.TempDir(), "dest"), opts...)
+			_, usageErr := filesystem.Usage(".", opts...)
+			_, globErr := filesystem.Glob(".", "**", opts...)
+			watchErr := filesystem.Watch(context.Background(), ".", func([]filesystem.Event) {}, opts...)
+
+			// Assert
+			for _, err := range []error{walkErr, copyErr, txErr, usageErr, globErr, watchErr} {
+				assert.ErrorIs(t, err, path.ErrBadPattern)
+			}
+		}
+	})
+}
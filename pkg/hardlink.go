@@ -0,0 +1,32 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// inode identifies a file on a given device.
+type inode struct {
+	dev uint64
+	ino uint64
+}
+
+// WithHardLinks specifies that CopyDir must recreate hard links between copied files.
+//
+// When two (or more) copied files share the same device and inode in the source directory,
+// the first one is copied and the others are hard linked to it in destination directory.
+// A file is copied instead whenever linking fails (cross-device destination, unsupported filesystem, etc.).
+func WithHardLinks() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.hardLinks = true
+	}
+}
+
+// link creates dest as a hard link to target, replacing dest if it already exists.
+func link(target, dest string) error {
+	if err := os.Remove(dest); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.Link(target, dest)
+}
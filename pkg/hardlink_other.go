@@ -0,0 +1,10 @@
+//go:build !unix
+
+package filesystem
+
+import "io/fs"
+
+// fileID is not supported outside unix platforms, hard linked files are always copied.
+func fileID(fs.FileInfo) (inode, bool) {
+	return inode{}, false
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileID returns the inode identifying info's file.
+// It returns false when info doesn't come from the OS filesystem or when the file has no other link.
+func fileID(info fs.FileInfo) (inode, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return inode{}, false
+	}
+	// Dev type isn't the same on all unix platforms
+	return inode{dev: uint64(stat.Dev), ino: stat.Ino}, true
+}
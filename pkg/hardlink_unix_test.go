@@ -0,0 +1,61 @@
+//go:build unix
+
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestCopyDir_HardLinks(t *testing.T) {
+	// Arrange
+	srcdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "file.txt"), []byte("hey file"), filesystem.RwRR))
+	require.NoError(t, os.Mkdir(filepath.Join(srcdir, "sub"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.Link(filepath.Join(srcdir, "file.txt"), filepath.Join(srcdir, "sub", "link.txt")))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "other.txt"), []byte("other"), filesystem.RwRR))
+
+	t.Run("success_copies", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir)
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, srcdir, destdir)
+		file, err := os.Stat(filepath.Join(destdir, "file.txt"))
+		require.NoError(t, err)
+		link, err := os.Stat(filepath.Join(destdir, "sub", "link.txt"))
+		require.NoError(t, err)
+		assert.False(t, os.SameFile(file, link))
+	})
+
+	t.Run("success_hard_links", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithHardLinks())
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, srcdir, destdir)
+		file, err := os.Stat(filepath.Join(destdir, "file.txt"))
+		require.NoError(t, err)
+		link, err := os.Stat(filepath.Join(destdir, "sub", "link.txt"))
+		require.NoError(t, err)
+		other, err := os.Stat(filepath.Join(destdir, "other.txt"))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(file, link))
+		assert.False(t, os.SameFile(file, other))
+	})
+}
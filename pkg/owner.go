@@ -0,0 +1,70 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// Chown represents a function to change the owner (uid and gid) of a given file.
+type Chown func(name string, uid, gid int) error
+
+// WithChown specifies a specific function to change the owner of destination files in CopyFile and CopyDir.
+//
+// It defaults to os.Lchown and only applies when one of WithOwner, WithPreserveOwner or WithOwnerMapping is given.
+func WithChown(chown Chown) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.chown = chown
+	}
+}
+
+// OwnerMapping represents a function computing the destination owner (uid and gid) from the source one.
+//
+// Source uid and gid are -1 when they can't be retrieved (non OS FS or unsupported platform).
+// Returning -1 for uid or gid leaves it unchanged.
+type OwnerMapping func(uid, gid int) (int, int)
+
+// WithOwnerMapping specifies a mapping to compute the owner of destination files in CopyFile and CopyDir.
+//
+// The owner is changed only when the current process is allowed to, otherwise it's silently skipped.
+func WithOwnerMapping(mapping OwnerMapping) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.owner = mapping
+	}
+}
+
+// WithOwner specifies the owner (uid and gid) of destination files in CopyFile and CopyDir.
+//
+// See WithOwnerMapping for more details.
+func WithOwner(uid, gid int) FSOption {
+	return WithOwnerMapping(func(int, int) (int, int) { return uid, gid })
+}
+
+// WithPreserveOwner specifies that destination files in CopyFile and CopyDir must keep the owner of source ones.
+//
+// See WithOwnerMapping for more details.
+func WithPreserveOwner() FSOption {
+	return WithOwnerMapping(func(uid, gid int) (int, int) { return uid, gid })
+}
+
+// applyOwner changes dest owner according to given options and src information.
+func applyOwner(o *fsOpt, info fs.FileInfo, dest string) error {
+	if o.owner == nil {
+		return nil
+	}
+
+	uid, gid := owner(info)
+	uid, gid = o.owner(uid, gid)
+	if uid == -1 && gid == -1 {
+		return nil
+	}
+
+	err := o.chown(dest, uid, gid)
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, errors.ErrUnsupported) {
+		return nil // not allowed to change owner (not root, no CAP_CHOWN, etc.)
+	}
+	return err
+}
+
+// defaultChown is the default function used to change the owner of destination files.
+var defaultChown Chown = os.Lchown
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package filesystem
+
+import "io/fs"
+
+// owner is not supported outside unix platforms, it always returns -1 for both uid and gid.
+func owner(fs.FileInfo) (int, int) {
+	return -1, -1
+}
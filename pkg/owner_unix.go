@@ -0,0 +1,20 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// owner returns the uid and gid owning info's file, -1 for both when they can't be retrieved.
+func owner(info fs.FileInfo) (int, int) {
+	if info == nil {
+		return -1, -1
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1
+	}
+	return int(stat.Uid), int(stat.Gid)
+}
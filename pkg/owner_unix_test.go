@@ -0,0 +1,147 @@
+//go:build unix
+
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+// owners records all calls to a filesystem.Chown function.
+type owners map[string][2]int
+
+func (o owners) chown(name string, uid, gid int) error {
+	o[name] = [2]int{uid, gid}
+	return nil
+}
+
+func TestCopyDir_Owner(t *testing.T) {
+	srcdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "file.txt"), []byte("hey file"), filesystem.RwRR))
+	require.NoError(t, os.Mkdir(filepath.Join(srcdir, "sub"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "sub", "file.txt"), []byte("hey file"), filesystem.RwRR))
+
+	info, err := os.Stat(srcdir)
+	require.NoError(t, err)
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	require.True(t, ok)
+	uid, gid := int(stat.Uid), int(stat.Gid)
+
+	t.Run("success_no_owner", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+		recorder := owners{}
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithChown(recorder.chown))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, recorder)
+	})
+
+	t.Run("success_preserve", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+		recorder := owners{}
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithChown(recorder.chown), filesystem.WithPreserveOwner())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, owners{
+			destdir:                                   {uid, gid},
+			filepath.Join(destdir, "file.txt"):        {uid, gid},
+			filepath.Join(destdir, "sub"):             {uid, gid},
+			filepath.Join(destdir, "sub", "file.txt"): {uid, gid},
+		}, recorder)
+	})
+
+	t.Run("success_mapping", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+		recorder := owners{}
+		mapping := func(uid, gid int) (int, int) { return uid + 1000, gid + 2000 }
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithChown(recorder.chown), filesystem.WithOwnerMapping(mapping))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, recorder, 4)
+		assert.Equal(t, [2]int{uid + 1000, gid + 2000}, recorder[filepath.Join(destdir, "sub", "file.txt")])
+	})
+
+	t.Run("success_forced_owner_without_stat", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{"file.txt": &fstest.MapFile{Data: []byte("hey file")}}
+		dest := filepath.Join(t.TempDir(), "file.txt")
+		recorder := owners{}
+
+		// Act
+		err := filesystem.CopyFile("file.txt", dest, filesystem.WithFS(fsys), filesystem.WithChown(recorder.chown), filesystem.WithOwner(42, 43))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, owners{dest: {42, 43}}, recorder)
+	})
+
+	t.Run("success_skip_not_permitted", func(t *testing.T) {
+		// Arrange
+		dest := filepath.Join(t.TempDir(), "file.txt")
+		chown := func(string, int, int) error { return &os.PathError{Op: "lchown", Path: dest, Err: syscall.EPERM} }
+
+		// Act
+		err := filesystem.CopyFile(filepath.Join(srcdir, "file.txt"), dest, filesystem.WithChown(chown), filesystem.WithOwner(42, 43))
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("success_os", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithPreserveOwner())
+
+		// Assert
+		require.NoError(t, err)
+		info, err := os.Stat(filepath.Join(destdir, "sub", "file.txt"))
+		require.NoError(t, err)
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		require.True(t, ok)
+		assert.Equal(t, uid, int(stat.Uid))
+		assert.Equal(t, gid, int(stat.Gid))
+	})
+
+	t.Run("success_keep_setuid", func(t *testing.T) {
+		if os.Getuid() != 0 {
+			t.Skip("changing owner needs root")
+		}
+
+		// Arrange
+		dest := filepath.Join(t.TempDir(), "file.txt")
+
+		// Act
+		err := filesystem.CopyFile(filepath.Join(srcdir, "file.txt"), dest, filesystem.WithPerm(0o755|os.ModeSetuid), filesystem.WithOwner(1000, 1000))
+
+		// Assert
+		require.NoError(t, err)
+		info, err := os.Stat(dest)
+		require.NoError(t, err)
+		assert.Equal(t, 0o755|os.ModeSetuid, info.Mode())
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		require.True(t, ok)
+		assert.Equal(t, 1000, int(stat.Uid))
+	})
+}
@@ -0,0 +1,81 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// blockSize is the size of chunks inspected when looking for zero runs to turn into holes.
+const blockSize = 4096
+
+// WithSparse specifies that CopyFile and CopyDir must preserve holes of sparse files.
+//
+// On Linux, holes of OS files are detected with SEEK_DATA and SEEK_HOLE.
+// For any other source (non OS FS or unsupported platform), runs of zeros are detected and recreated as holes.
+func WithSparse() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.sparse = true
+	}
+}
+
+// copySparse copies src into dest by recreating holes instead of writing zeros.
+func copySparse(dest *os.File, src io.Reader) error {
+	if file, ok := src.(*os.File); ok {
+		handled, err := copyHoles(dest, file)
+		if err != nil || handled {
+			return err
+		}
+	}
+
+	size, err := copyZeroRuns(dest, src)
+	if err != nil {
+		return err
+	}
+
+	// truncate is needed to materialize trailing holes since they were only skipped
+	if err := dest.Truncate(size); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", dest.Name(), err)
+	}
+	return nil
+}
+
+// copyZeroRuns copies src into dest by seeking over blocks only made of zeros.
+//
+// It returns the number of bytes read from src, which is the expected dest size.
+func copyZeroRuns(dest *os.File, src io.Reader) (int64, error) {
+	buf := make([]byte, blockSize)
+
+	var size int64
+	for {
+		n, err := io.ReadFull(src, buf)
+		if n > 0 {
+			if isZero(buf[:n]) {
+				if _, err := dest.Seek(int64(n), io.SeekCurrent); err != nil {
+					return 0, fmt.Errorf("failed to seek %s: %w", dest.Name(), err)
+				}
+			} else if _, err := dest.Write(buf[:n]); err != nil {
+				return 0, fmt.Errorf("failed to write %s: %w", dest.Name(), err)
+			}
+			size += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return size, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read: %w", err)
+		}
+	}
+}
+
+// isZero returns true if all bytes in buf are zeros.
+func isZero(buf []byte) bool {
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
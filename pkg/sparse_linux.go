@@ -0,0 +1,79 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+const (
+	// seekData is the whence for lseek to go to the next data region (SEEK_DATA).
+	seekData = 3
+	// seekHole is the whence for lseek to go to the next hole (SEEK_HOLE).
+	seekHole = 4
+)
+
+// copyHoles copies src into dest by copying only data regions reported by SEEK_DATA and SEEK_HOLE.
+//
+// It returns false when src isn't a regular file, reports a zero size (e.g. procfs or sysfs files)
+// or when the underlying filesystem doesn't support those lseek operations.
+func copyHoles(dest, src *os.File) (bool, error) {
+	stat, err := src.Stat()
+	if err != nil {
+		return true, fmt.Errorf("failed to stat %s: %w", src.Name(), err)
+	}
+	size := stat.Size()
+	if !stat.Mode().IsRegular() || size == 0 {
+		return false, nil
+	}
+
+	var offset int64
+	for offset < size {
+		data, err := src.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			break // no more data after offset, only a trailing hole
+		}
+		if err != nil {
+			if offset == 0 && errors.Is(err, syscall.EINVAL) {
+				return false, nil // SEEK_DATA not supported
+			}
+			return true, fmt.Errorf("failed to seek data in %s: %w", src.Name(), err)
+		}
+
+		hole, err := src.Seek(data, seekHole)
+		if err != nil {
+			return true, fmt.Errorf("failed to seek hole in %s: %w", src.Name(), err)
+		}
+
+		if _, err := src.Seek(data, io.SeekStart); err != nil {
+			return true, fmt.Errorf("failed to seek %s: %w", src.Name(), err)
+		}
+		if _, err := dest.Seek(data, io.SeekStart); err != nil {
+			return true, fmt.Errorf("failed to seek %s: %w", dest.Name(), err)
+		}
+		if _, err := io.CopyN(dest, src, hole-data); err != nil {
+			return true, fmt.Errorf("failed to copy data region: %w", err)
+		}
+		offset = hole
+	}
+
+	// src may have grown since stat, remaining bytes are copied as is
+	if _, err := src.Seek(size, io.SeekStart); err != nil {
+		return true, fmt.Errorf("failed to seek %s: %w", src.Name(), err)
+	}
+	if _, err := dest.Seek(size, io.SeekStart); err != nil {
+		return true, fmt.Errorf("failed to seek %s: %w", dest.Name(), err)
+	}
+	remaining, err := io.Copy(dest, src)
+	if err != nil {
+		return true, fmt.Errorf("failed to copy data region: %w", err)
+	}
+
+	// truncate is needed to materialize the trailing hole
+	if err := dest.Truncate(size + remaining); err != nil {
+		return true, fmt.Errorf("failed to truncate %s: %w", dest.Name(), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,105 @@
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+// blocks returns the number of 512-bytes blocks allocated for name.
+func blocks(t *testing.T, name string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	require.True(t, ok)
+	return stat.Blocks
+}
+
+func TestCopyFile_Sparse(t *testing.T) {
+	const size = 8 << 20 // 8 MiB
+
+	t.Run("success_os", func(t *testing.T) {
+		// Arrange
+		tmp := t.TempDir()
+		src := filepath.Join(tmp, "disk.img")
+		dest := filepath.Join(tmp, "copy.img")
+
+		file, err := os.Create(src)
+		require.NoError(t, err)
+		_, err = file.WriteAt([]byte("header"), 0)
+		require.NoError(t, err)
+		_, err = file.WriteAt([]byte("middle"), size/2)
+		require.NoError(t, err)
+		require.NoError(t, file.Truncate(size))
+		require.NoError(t, file.Close())
+
+		// Act
+		err = filesystem.CopyFile(src, dest, filesystem.WithSparse())
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, src, dest)
+		info, err := os.Stat(dest)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, info.Size())
+		assert.LessOrEqual(t, blocks(t, dest), blocks(t, src))
+		assert.Less(t, blocks(t, dest)*512, int64(size/8))
+	})
+
+	t.Run("success_zero_runs", func(t *testing.T) {
+		// Arrange
+		content := make([]byte, size)
+		copy(content[size/2:], "middle")
+		fsys := fstest.MapFS{"disk.img": &fstest.MapFile{Data: content}}
+		dest := filepath.Join(t.TempDir(), "copy.img")
+
+		// Act
+		err := filesystem.CopyFile("disk.img", dest, filesystem.WithFS(fsys), filesystem.WithSparse())
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, content, bytes)
+		assert.Less(t, blocks(t, dest)*512, int64(size/8))
+	})
+
+	t.Run("success_not_sparse", func(t *testing.T) {
+		// Arrange
+		content := make([]byte, size)
+		fsys := fstest.MapFS{"disk.img": &fstest.MapFile{Data: content}}
+		dest := filepath.Join(t.TempDir(), "copy.img")
+
+		// Act
+		err := filesystem.CopyFile("disk.img", dest, filesystem.WithFS(fsys))
+
+		// Assert
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, blocks(t, dest)*512, int64(size))
+	})
+
+	t.Run("success_zero_size", func(t *testing.T) {
+		// Arrange
+		// procfs files report a zero size while having content
+		dest := filepath.Join(t.TempDir(), "status")
+
+		// Act
+		err := filesystem.CopyFile("/proc/self/status", dest, filesystem.WithSparse())
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Contains(t, string(bytes), "Name:")
+	})
+}
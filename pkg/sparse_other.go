@@ -0,0 +1,10 @@
+//go:build !linux
+
+package filesystem
+
+import "os"
+
+// copyHoles is not supported outside Linux, zero runs detection is used instead.
+func copyHoles(_, _ *os.File) (bool, error) {
+	return false, nil
+}
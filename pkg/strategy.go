@@ -0,0 +1,67 @@
+package filesystem
+
+import "errors"
+
+// ErrorAction represents the decision taken when copying a file or a directory fails in CopyDir.
+type ErrorAction int
+
+const (
+	// ActionContinue keeps the error (it will be part of CopyDir returned error) and continues with next files and directories.
+	ActionContinue ErrorAction = iota
+	// ActionSkip ignores the error (it won't be part of CopyDir returned error) and continues with next files and directories.
+	ActionSkip
+	// ActionRetry retries the failed operation on the same file or directory.
+	ActionRetry
+	// ActionAbort stops the copy, CopyDir returns all errors kept so far including this one.
+	ActionAbort
+)
+
+// OnError represents a function deciding what to do when copying a file or a directory fails in CopyDir.
+//
+// In case of ActionRetry, it will be called again if the retried operation fails another time,
+// it's up to the function to count attempts and give up at some point.
+type OnError func(err *CopyError) ErrorAction
+
+// WithOnError specifies a function to decide what to do with each failure in CopyDir (see ErrorAction).
+//
+// The default behavior is to continue and collect all errors (ActionContinue).
+// It applies to all files and directories, including nested ones.
+func WithOnError(onError OnError) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.onError = onError
+	}
+}
+
+// WithFailFast specifies that CopyDir must stop at the first failure.
+func WithFailFast() FSOption {
+	return WithOnError(func(*CopyError) ErrorAction { return ActionAbort })
+}
+
+// try runs op and applies the error strategy on its failure.
+//
+// It returns the error to keep, nil in case op succeeded or its error was skipped.
+func (c *copier) try(op func() error) error {
+	for {
+		err := op()
+		if err == nil || c.onError == nil {
+			return err
+		}
+
+		var cerr *CopyError
+		if !errors.As(err, &cerr) {
+			cerr = &CopyError{Err: err}
+		}
+
+		switch c.onError(cerr) {
+		case ActionRetry:
+			continue
+		case ActionSkip:
+			return nil
+		case ActionAbort:
+			c.aborted = true
+			return err
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestCopyDir_ErrorStrategy(t *testing.T) {
+	srcdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "a.txt"), []byte("a"), filesystem.RwRR))
+	require.NoError(t, os.Mkdir(filepath.Join(srcdir, "b"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "b", "c.txt"), []byte("c"), filesystem.RwRR))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "d.txt"), []byte("d"), filesystem.RwRR))
+
+	// destdir returns a destination directory where a.txt and b/c.txt creation will fail
+	destdir := func(t *testing.T) string {
+		t.Helper()
+		destdir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(destdir, "a.txt"), filesystem.RwxRxRxRx))
+		require.NoError(t, os.MkdirAll(filepath.Join(destdir, "b", "c.txt"), filesystem.RwxRxRxRx))
+		return destdir
+	}
+
+	t.Run("error_continue", func(t *testing.T) {
+		// Arrange
+		destdir := destdir(t)
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir)
+
+		// Assert
+		assert.Len(t, filesystem.CopyErrors(err), 2)
+		assert.FileExists(t, filepath.Join(destdir, "d.txt"))
+	})
+
+	t.Run("error_fail_fast", func(t *testing.T) {
+		// Arrange
+		destdir := destdir(t)
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithFailFast())
+
+		// Assert
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, filepath.Join(destdir, "a.txt"), errs[0].Dest)
+		assert.NoFileExists(t, filepath.Join(destdir, "d.txt"))
+	})
+
+	t.Run("error_abort_nested", func(t *testing.T) {
+		// Arrange
+		destdir := destdir(t)
+		onError := func(err *filesystem.CopyError) filesystem.ErrorAction {
+			if strings.HasPrefix(err.Dest, filepath.Join(destdir, "b")) {
+				return filesystem.ActionAbort
+			}
+			return filesystem.ActionContinue
+		}
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithOnError(onError))
+
+		// Assert
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 2)
+		assert.Equal(t, filepath.Join(destdir, "b", "c.txt"), errs[1].Dest)
+		assert.NoFileExists(t, filepath.Join(destdir, "d.txt"))
+	})
+
+	t.Run("success_skip", func(t *testing.T) {
+		// Arrange
+		destdir := destdir(t)
+		var skipped []string
+		onError := func(err *filesystem.CopyError) filesystem.ErrorAction {
+			skipped = append(skipped, err.Dest)
+			return filesystem.ActionSkip
+		}
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithOnError(onError))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(destdir, "a.txt"), filepath.Join(destdir, "b", "c.txt")}, skipped)
+		assert.FileExists(t, filepath.Join(destdir, "d.txt"))
+	})
+
+	t.Run("success_retry", func(t *testing.T) {
+		// Arrange
+		destdir := destdir(t)
+		attempts := 0
+		onError := func(err *filesystem.CopyError) filesystem.ErrorAction {
+			attempts++
+			if attempts > 2 {
+				return filesystem.ActionAbort
+			}
+			// remove the directory preventing file creation
+			if err := os.Remove(err.Dest); err != nil {
+				return filesystem.ActionAbort
+			}
+			return filesystem.ActionRetry
+		}
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithOnError(onError))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.FileExists(t, filepath.Join(destdir, "a.txt"))
+		assert.FileExists(t, filepath.Join(destdir, "b", "c.txt"))
+		assert.FileExists(t, filepath.Join(destdir, "d.txt"))
+	})
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+const (
+	// sniffLen is the number of bytes inspected by IsBinary when looking for a NUL byte.
+	sniffLen = 8000
+
+	// dumpWidth is the number of bytes by line in BinaryDiff hex dumps.
+	dumpWidth = 16
+
+	// dumpLines is the number of lines printed before and after the first difference in BinaryDiff hex dumps.
+	dumpLines = 2
+)
+
+// IsBinary returns true if content must be considered as binary, i.e. it contains a NUL byte in its first 8000 bytes.
+//
+// Like git, other encodings than UTF-8 (e.g. Latin-1) are considered as text.
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), sniffLen)], 0) >= 0
+}
+
+// BinaryDiff returns a report of the differences between old and new binary contents.
+// If old and new are identical, BinaryDiff returns a nil slice (no output).
+//
+// The report contains both sizes and SHA-256 hashes, the offset of the first differing byte
+// and a hex dump of both contents around this offset.
+func BinaryDiff(oldName string, old []byte, newName string, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "binary files %s and %s differ\n", oldName, newName)
+	fmt.Fprintf(&out, "--- %s (%d bytes, sha256 %x)\n", oldName, len(old), sha256.Sum256(old))
+	fmt.Fprintf(&out, "+++ %s (%d bytes, sha256 %x)\n", newName, len(new), sha256.Sum256(new))
+
+	offset := firstDiff(old, new)
+	fmt.Fprintf(&out, "first difference at offset %d (%#x)\n", offset, offset)
+
+	// dump surrounding lines, aligned on dumpWidth
+	start := max(offset/dumpWidth-dumpLines, 0) * dumpWidth
+	end := (offset/dumpWidth + dumpLines + 1) * dumpWidth
+	fmt.Fprintf(&out, "--- %s\n", oldName)
+	dump(&out, old, start, end)
+	fmt.Fprintf(&out, "+++ %s\n", newName)
+	dump(&out, new, start, end)
+	return out.Bytes()
+}
+
+// firstDiff returns the offset of the first differing byte between old and new.
+//
+// When one is a prefix of the other, the length of the shortest one is returned.
+func firstDiff(old, new []byte) int {
+	n := min(len(old), len(new))
+	for i := range n {
+		if old[i] != new[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// dump writes into out a hex dump (in hexdump -C format) of content between start and end offsets.
+func dump(out *bytes.Buffer, content []byte, start, end int) {
+	end = min(end, len(content))
+	if start >= end {
+		fmt.Fprintf(out, "%08x  (end of content)\n", len(content))
+		return
+	}
+
+	for line := start; line < end; line += dumpWidth {
+		chunk := content[line:min(line+dumpWidth, end)]
+
+		fmt.Fprintf(out, "%08x ", line)
+		for i := range dumpWidth {
+			if i%8 == 0 {
+				out.WriteByte(' ')
+			}
+			if i < len(chunk) {
+				fmt.Fprintf(out, "%02x ", chunk[i])
+			} else {
+				out.WriteString("   ")
+			}
+		}
+
+		out.WriteString(" |")
+		for _, b := range chunk {
+			if b < 32 || b > 126 {
+				b = '.'
+			}
+			out.WriteByte(b)
+		}
+		out.WriteString("|\n")
+	}
+}
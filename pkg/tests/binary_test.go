@@ -0,0 +1,113 @@
+package tests_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestIsBinary(t *testing.T) {
+	cases := []struct {
+		name    string
+		content []byte
+		binary  bool
+	}{
+		{name: "empty", content: nil, binary: false},
+		{name: "text", content: []byte("hey file\r\n"), binary: false},
+		{name: "utf8", content: []byte("café\n"), binary: false},
+		{name: "latin1", content: []byte("caf\xe9\r\n"), binary: false},
+		{name: "nul", content: []byte("\x00\x01\x02"), binary: true},
+		{name: "nul_after_text", content: []byte("text\x00"), binary: true},
+		{name: "nul_after_sniff_len", content: append(bytes.Repeat([]byte("a"), 8000), 0), binary: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Act
+			binary := tests.IsBinary(c.content)
+
+			// Assert
+			assert.Equal(t, c.binary, binary)
+		})
+	}
+}
+
+func TestBinaryDiff(t *testing.T) {
+	t.Run("success_equal", func(t *testing.T) {
+		// Act
+		diff := tests.BinaryDiff("old.bin", []byte("\x00a"), "new.bin", []byte("\x00a"))
+
+		// Assert
+		assert.Nil(t, diff)
+	})
+
+	t.Run("success_summary", func(t *testing.T) {
+		// Arrange
+		old := []byte("\x00a")
+		new := []byte("\x00ab")
+
+		// Act
+		diff := tests.BinaryDiff("old.bin", old, "new.bin", new)
+
+		// Assert
+		expected := "binary files old.bin and new.bin differ\n" +
+			fmt.Sprintf("--- old.bin (2 bytes, sha256 %x)\n", sha256.Sum256(old)) +
+			fmt.Sprintf("+++ new.bin (3 bytes, sha256 %x)\n", sha256.Sum256(new)) +
+			"first difference at offset 2 (0x2)\n" +
+			"--- old.bin\n" +
+			"00000000  00 61                                             |.a|\n" +
+			"+++ new.bin\n" +
+			"00000000  00 61 62                                          |.ab|\n"
+		assert.Equal(t, expected, string(diff))
+	})
+
+	t.Run("success_dump_window", func(t *testing.T) {
+		// Arrange
+		old := append([]byte{0}, bytes.Repeat([]byte("0123456789abcdef"), 8)...)
+		new := bytes.Clone(old)
+		new[100] = 'X'
+
+		// Act
+		diff := tests.BinaryDiff("old.bin", old, "new.bin", new)
+
+		// Assert
+		assert.Contains(t, string(diff), "first difference at offset 100 (0x64)\n")
+		// two lines before and after the line with the difference
+		assert.NotContains(t, string(diff), "00000030 ")
+		assert.Contains(t, string(diff), "00000040  ")
+		assert.Contains(t, string(diff), "00000060  66 30 31 32 58 34 35 36  37 38 39 61 62 63 64 65  |f012X456789abcde|\n")
+		assert.Contains(t, string(diff), "00000080  66                                                |f|\n")
+	})
+
+	t.Run("success_empty", func(t *testing.T) {
+		// Act
+		diff := tests.BinaryDiff("old.bin", []byte("\x00a"), "new.bin", nil)
+
+		// Assert
+		assert.Contains(t, string(diff), "+++ new.bin\n00000000  (end of content)\n")
+	})
+}
+
+func TestAssertEqualFile_Binary(t *testing.T) {
+	t.Run("error_binary", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "\x00old", "\x00new")
+
+		// Assert
+		assert.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "binary files")
+		assert.Contains(t, errs[0], "first difference at offset 1 (0x1)")
+	})
+
+	t.Run("success_latin1_carriage_returns", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "caf\xe9\r\n", "caf\xe9\n")
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+}
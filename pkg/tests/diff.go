@@ -1,4 +1,5 @@
-// Code copied from https://github.com/golang/go/tree/master/src/internal/diff/diff.go; DO NOT EDIT.
+// Code copied from https://github.com/golang/go/tree/master/src/internal/diff/diff.go,
+// only edited to support options (see DiffOption) and structured hunks (see Hunks).
 
 // Copyright 2022 The Go Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
@@ -45,32 +46,46 @@ type pair struct{ x, y int }
 // Second, the name is frequently interpreted as meaning that you have
 // to wait longer (to be patient) for the diff, meaning that it is a slower algorithm,
 // when in fact the algorithm is faster than the standard one.
-func Diff(oldName string, old []byte, newName string, new []byte) []byte {
-	if bytes.Equal(old, new) {
+//
+// Options can be given to change the number of context lines (WithContext)
+// or to use the Myers algorithm instead (WithAlgorithm).
+func Diff(oldName string, old []byte, newName string, new []byte, opts ...DiffOption) []byte {
+	hunks := Hunks(old, new, opts...)
+	if len(hunks) == 0 {
 		return nil
 	}
-	x := lines(old)
-	y := lines(new)
 
 	// Print diff header.
 	var out bytes.Buffer
 	fmt.Fprintf(&out, "diff %s %s\n", oldName, newName)
-	fmt.Fprintf(&out, "--- %s\n", oldName)
-	fmt.Fprintf(&out, "+++ %s\n", newName)
+	out.Write(FormatHunks(oldName, newName, hunks))
+	return out.Bytes()
+}
+
+// Hunks returns the differences between old and new texts as hunks (see Diff for algorithms and options).
+// If old and new are identical, Hunks returns a nil slice.
+func Hunks(old, new []byte, opts ...DiffOption) []Hunk {
+	o := newDiffOpt(opts...)
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	x := lines(old)
+	y := lines(new)
 
 	// Loop over matches to consider,
 	// expanding each match to include surrounding lines,
-	// and then printing diff chunks.
+	// and then building diff chunks.
 	// To avoid setup/teardown cases outside the loop,
-	// tgs returns a leading {0,0} and trailing {len(x), len(y)} pair
+	// matches returns a leading {0,0} and trailing {len(x), len(y)} pair
 	// in the sequence of matches.
 	var (
-		done  pair     // printed up to x[:done.x] and y[:done.y]
-		chunk pair     // start lines of current chunk
-		count pair     // number of lines from each side in current chunk
-		ctext []string // lines for current chunk
+		hunks []Hunk
+		done  pair       // built up to x[:done.x] and y[:done.y]
+		chunk pair       // start lines of current chunk
+		count pair       // number of lines from each side in current chunk
+		ctext []DiffLine // lines for current chunk
 	)
-	for _, m := range tgs(x, y) {
+	for _, m := range o.matches(x, y) {
 		if m.x < done.x {
 			// Already handled scanning forward from earlier match.
 			continue
@@ -94,21 +109,21 @@ func Diff(oldName string, old []byte, newName string, new []byte) []byte {
 		// Emit the mismatched lines before start into this chunk.
 		// (No effect on first sentinel iteration, when start = {0,0}.)
 		for _, s := range x[done.x:start.x] {
-			ctext = append(ctext, "-"+s)
+			ctext = append(ctext, diffLine(LineDelete, s))
 			count.x++
 		}
 		for _, s := range y[done.y:start.y] {
-			ctext = append(ctext, "+"+s)
+			ctext = append(ctext, diffLine(LineInsert, s))
 			count.y++
 		}
 
 		// If we're not at EOF and have too few common lines,
 		// the chunk includes all the common lines and continues.
-		const C = 3 // number of context lines
+		C := o.context // number of context lines
 		if (end.x < len(x) || end.y < len(y)) &&
 			(end.x-start.x < C || (len(ctext) > 0 && end.x-start.x < 2*C)) {
 			for _, s := range x[start.x:end.x] {
-				ctext = append(ctext, " "+s)
+				ctext = append(ctext, diffLine(LineEqual, s))
 				count.x++
 				count.y++
 			}
@@ -123,13 +138,13 @@ func Diff(oldName string, old []byte, newName string, new []byte) []byte {
 				n = C
 			}
 			for _, s := range x[start.x : start.x+n] {
-				ctext = append(ctext, " "+s)
+				ctext = append(ctext, diffLine(LineEqual, s))
 				count.x++
 				count.y++
 			}
 			done = pair{start.x + n, start.y + n}
 
-			// Format and emit chunk.
+			// Emit chunk.
 			// Convert line numbers to 1-indexed.
 			// Special case: empty file shows up as 0,0 not 1,0.
 			if count.x > 0 {
@@ -138,13 +153,16 @@ func Diff(oldName string, old []byte, newName string, new []byte) []byte {
 			if count.y > 0 {
 				chunk.y++
 			}
-			fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", chunk.x, count.x, chunk.y, count.y)
-			for _, s := range ctext {
-				out.WriteString(s)
-			}
+			hunks = append(hunks, Hunk{
+				OldStart: chunk.x,
+				OldLines: count.x,
+				NewStart: chunk.y,
+				NewLines: count.y,
+				Lines:    ctext,
+			})
 			count.x = 0
 			count.y = 0
-			ctext = ctext[:0]
+			ctext = nil
 		}
 
 		// If we reached EOF, we're done.
@@ -155,14 +173,14 @@ func Diff(oldName string, old []byte, newName string, new []byte) []byte {
 		// Otherwise start a new chunk.
 		chunk = pair{end.x - C, end.y - C}
 		for _, s := range x[chunk.x:end.x] {
-			ctext = append(ctext, " "+s)
+			ctext = append(ctext, diffLine(LineEqual, s))
 			count.x++
 			count.y++
 		}
 		done = end
 	}
 
-	return out.Bytes()
+	return hunks
 }
 
 // lines returns the lines in the file x, including newlines.
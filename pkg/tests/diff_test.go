@@ -0,0 +1,178 @@
+package tests_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+// text returns a text with one line for each space separated word of s.
+func text(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(strings.Join(strings.Fields(s), "\n") + "\n")
+}
+
+// edits returns the number of deleted and inserted lines in hunks.
+func edits(hunks []tests.Hunk) int {
+	count := 0
+	for _, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			if line.Op != tests.LineEqual {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// lcs returns the length of the longest common subsequence of x and y.
+func lcs(x, y []string) int {
+	lengths := make([][]int, len(x)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func TestDiff_Myers(t *testing.T) {
+	myers := tests.WithAlgorithm(tests.AlgorithmMyers)
+
+	t.Run("success_empty", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", nil, "b", nil, myers)
+
+		// Assert
+		assert.Nil(t, diff)
+	})
+
+	t.Run("success_all_insert", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", nil, "b", text("1 2"), myers)
+
+		// Assert
+		assert.Equal(t, "diff a b\n--- a\n+++ b\n@@ -0,0 +1,2 @@\n+1\n+2\n", string(diff))
+	})
+
+	t.Run("success_all_delete", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", text("1 2"), "b", nil, myers)
+
+		// Assert
+		assert.Equal(t, "diff a b\n--- a\n+++ b\n@@ -1,2 +0,0 @@\n-1\n-2\n", string(diff))
+	})
+
+	t.Run("success_all_replaced", func(t *testing.T) {
+		// Act
+		hunks := tests.Hunks(text("1 2"), text("3 4 5"), myers)
+
+		// Assert
+		assert.Equal(t, 5, edits(hunks))
+	})
+
+	t.Run("success_paper_example", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", text("A B C A B B A"), "b", text("C B A B A C"), myers)
+
+		// Assert
+		expected := "diff a b\n--- a\n+++ b\n@@ -1,7 +1,6 @@\n-A\n-B\n C\n+B\n A\n B\n-B\n A\n+C\n"
+		assert.Equal(t, expected, string(diff))
+	})
+
+	t.Run("success_minimal", func(t *testing.T) {
+		cases := []struct {
+			old   string
+			new   string
+			edits int
+		}{
+			{old: "a b c", new: "a c", edits: 1},
+			{old: "a b c d", new: "b c d e", edits: 2},
+			{old: "A B C A B B A", new: "C B A B A C", edits: 5},
+			{old: "a a a b", new: "b a a a", edits: 2},
+			{old: "} } x }", new: "} x } }", edits: 2},
+		}
+		for _, c := range cases {
+			// Act
+			hunks := tests.Hunks(text(c.old), text(c.new), myers)
+
+			// Assert
+			assert.Equal(t, c.edits, edits(hunks), c.old+" -> "+c.new)
+		}
+	})
+
+	t.Run("success_minimal_random", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		words := func() []string {
+			words := make([]string, r.Intn(12))
+			for i := range words {
+				words[i] = string(rune('a' + r.Intn(3)))
+			}
+			return words
+		}
+
+		for range 500 {
+			// Arrange
+			x, y := words(), words()
+
+			// Act
+			hunks := tests.Hunks(text(strings.Join(x, " ")), text(strings.Join(y, " ")), myers)
+
+			// Assert
+			assert.Equal(t, len(x)+len(y)-2*lcs(x, y), edits(hunks), "%v -> %v", x, y)
+		}
+	})
+}
+
+func TestDiff_Context(t *testing.T) {
+	old := text("1 2 3 4 5 6 7 8 9")
+	new := text("1 2 x 4 5 6 7 8 y")
+
+	t.Run("success_default", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", old, "b", new)
+
+		// Assert
+		expected := "diff a b\n--- a\n+++ b\n@@ -1,9 +1,9 @@\n 1\n 2\n-3\n+x\n 4\n 5\n 6\n 7\n 8\n-9\n+y\n"
+		assert.Equal(t, expected, string(diff))
+	})
+
+	t.Run("success_one", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", old, "b", new, tests.WithContext(1))
+
+		// Assert
+		expected := "diff a b\n--- a\n+++ b\n@@ -2,3 +2,3 @@\n 2\n-3\n+x\n 4\n@@ -8,2 +8,2 @@\n 8\n-9\n+y\n"
+		assert.Equal(t, expected, string(diff))
+	})
+
+	t.Run("success_zero", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", old, "b", new, tests.WithContext(0))
+
+		// Assert
+		expected := "diff a b\n--- a\n+++ b\n@@ -3,1 +3,1 @@\n-3\n+x\n@@ -9,1 +9,1 @@\n-9\n+y\n"
+		assert.Equal(t, expected, string(diff))
+	})
+
+	t.Run("success_negative", func(t *testing.T) {
+		// Act
+		diff := tests.Diff("a", old, "b", new, tests.WithContext(-1))
+
+		// Assert
+		assert.Equal(t, tests.Diff("a", old, "b", new, tests.WithContext(0)), diff)
+	})
+}
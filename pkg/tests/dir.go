@@ -1,86 +1,219 @@
 package tests
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-// readDirInMap reads a given input directory (and its subdirectories) and returns a map with filenames as keys and content (string) as values.
-//
-// Collision will occur in case a two files with the same name exists (between root and subdirectory).
-func readDirInMap(srcdir string) (map[string][]byte, error) {
-	files := map[string][]byte{}
+// entry represents a file, a directory or a symbolic link read by readDirInMap.
+type entry struct {
+	// typ is the type of the entry (fs.ModeDir, fs.ModeSymlink or 0 for a regular file).
+	typ fs.FileMode
+	// perm is the permission bits of the entry.
+	perm fs.FileMode
+	// content is the content of a file (or symbolic link target file), always nil for directories.
+	content []byte
+	// target is the target of a symbolic link.
+	target string
+}
+
+// equal returns true if both entries are identical according to given options.
+func (e entry) equal(other entry, o *assertOpt) bool {
+	if e.typ != other.typ || (o.modes && e.perm != other.perm) {
+		return false
+	}
+	if e.typ == fs.ModeSymlink && o.symlinks {
+		return e.target == other.target
+	}
+	return bytes.Equal(o.normalize(e.content), o.normalize(other.content))
+}
 
-	entries, err := os.ReadDir(srcdir)
+// typeName returns a human readable name of a type of entry.
+func typeName(typ fs.FileMode) string {
+	switch typ {
+	case fs.ModeDir:
+		return "a directory"
+	case fs.ModeSymlink:
+		return "a symbolic link"
+	case 0:
+		return "a file"
+	default:
+		return "a special file (" + typ.String() + ")"
+	}
+}
+
+// readDirInMap reads a given input directory (and its subdirectories) and returns a map
+// with relative paths (slash separated) as keys and entries (type and content) as values.
+func readDirInMap(srcdir string) (map[string]entry, error) {
+	return readFSInMap(newDirFS(srcdir))
+}
+
+// readFSInMap reads the given filesystem (from its root) and returns a map
+// with relative paths (slash separated) as keys and entries (type and content) as values.
+func readFSInMap(fsys fs.FS) (map[string]entry, error) {
+	entries := map[string]entry{}
+	return entries, readDir(fsys, ".", entries)
+}
+
+// readDir reads srcdir from fsys and adds all its entries (recursively) into entries.
+func readDir(fsys fs.FS, srcdir string, entries map[string]entry) error {
+	dirEntries, err := fs.ReadDir(fsys, srcdir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read directory %s: %w", srcdir, err)
+		return fmt.Errorf("failed to read directory %s: %w", srcdir, err)
 	}
 
-	errs := make([]error, 0, len(entries))
-	for _, entry := range entries {
-		src := filepath.Join(srcdir, entry.Name())
+	errs := make([]error, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := path.Join(srcdir, dirEntry.Name())
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to stat %s: %w", name, err))
+			continue
+		}
 
 		// handle directories
-		if entry.IsDir() {
-			sub, err := readDirInMap(src)
-			if err != nil {
-				errs = append(errs, err) // only case of error is if reading an entry fails
-			}
+		if dirEntry.IsDir() {
+			entries[name] = entry{typ: fs.ModeDir, perm: info.Mode().Perm()}
+			errs = append(errs, readDir(fsys, name, entries)) // only case of error is if reading an entry fails
+			continue
+		}
 
-			for filename, content := range sub {
-				// NOTE collision on identical filenames between root and subdirectories
-				files[filename] = content
+		// handle symbolic links, their target content is read when possible
+		if dirEntry.Type()&fs.ModeSymlink != 0 {
+			var target string
+			if fsys, ok := fsys.(readLinkFS); ok {
+				if target, err = fsys.ReadLink(name); err != nil {
+					errs = append(errs, fmt.Errorf("failed to read link %s: %w", name, err))
+					continue
+				}
 			}
+			content, _ := fs.ReadFile(fsys, name) // target may not exist or be a directory
+			entries[name] = entry{typ: fs.ModeSymlink, perm: info.Mode().Perm(), content: content, target: target}
 			continue
 		}
 
 		// handle files
-		bytes, err := os.ReadFile(src)
+		content, err := fs.ReadFile(fsys, name)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to read %s: %w", src, err))
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", name, err))
 			continue
 		}
-		files[entry.Name()] = FilterCarriage(bytes)
+		entries[name] = entry{typ: dirEntry.Type(), perm: info.Mode().Perm(), content: content}
+	}
+	return errors.Join(errs...)
+}
+
+// sortedKeys returns the sorted keys of entries.
+func sortedKeys(entries map[string]entry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// nonEmptyDirs returns the set of directories having at least one child in entries.
+func nonEmptyDirs(entries map[string]entry) map[string]struct{} {
+	dirs := map[string]struct{}{}
+	for name := range entries {
+		if dir := path.Dir(name); dir != "." {
+			dirs[dir] = struct{}{}
+		}
 	}
-	return files, errors.Join(errs...)
+	return dirs
 }
 
 // AssertEqualDir compares expected an actual directories (and their subdirectories).
 //
+// Files and directories are compared by their path relative to expected and actual.
 // It will fail with t in case a file is missing in actual,
-// a file is present in actual but not in expected
+// a file is present in actual but not in expected,
+// a path has a different type (file, directory or symbolic link) in actual and expected
 // or if the content of any file in actual is not the same as its peer in expected.
-func AssertEqualDir(t testing.TB, expected, actual string) {
-	// read all files in expected directory
-	expectedFiles, err := readDirInMap(expected)
-	assert.NoError(t, err, "failed to completely read expected %s folder and its children", expected)
+//
+// Options can be given to compare permission bits (WithModes), symbolic links targets (WithSymlinkTargets)
+// and empty directories (WithEmptyDirs), to ignore some paths (WithIgnore)
+// or to normalize contents before comparison (WithTransform, WithReplace, WithTrimTrailingSpace).
+//
+// In update mode (see Updating), expected directory is updated to match actual directory instead.
+func AssertEqualDir(t testing.TB, expected, actual string, opts ...AssertOption) {
+	o := newAssertOpt(opts...)
+
+	// read all files in expected directory, it may not exist yet in update mode
+	expectedEntries, err := readDirInMap(expected)
+	if !Updating() || !errors.Is(err, fs.ErrNotExist) {
+		assert.NoError(t, err, "failed to completely read expected %s folder and its children", expected)
+	}
+	expectedEntries = o.filter(expectedEntries)
 
 	// read all files in actual directory
-	actualFiles, err := readDirInMap(actual)
+	actualEntries, err := readDirInMap(actual)
 	assert.NoError(t, err, "failed to completely read actual %s folder and its children", actual)
+	actualEntries = o.filter(actualEntries)
+
+	if Updating() {
+		err := updateDir(t, expected, actual, expectedEntries, actualEntries, o)
+		assert.NoError(t, err, "failed to update expected %s folder", expected)
+		return
+	}
+	assertEqualEntries(t, expectedEntries, actualEntries, o)
+}
 
-	// check all expected contents against actual contents
-	for filename, expectedBytes := range expectedFiles {
-		actualBytes, ok := actualFiles[filename]
-		assert.True(t, ok, "%s missing from actual directory", filename)
+// assertEqualEntries compares expected and actual entries (see readDirInMap) and fails with t on any difference.
+func assertEqualEntries(t testing.TB, expectedEntries, actualEntries map[string]entry, o *assertOpt) {
+	// empty directories are ignored when missing on one side, except when asked
+	expectedDirs := nonEmptyDirs(expectedEntries)
+	actualDirs := nonEmptyDirs(actualEntries)
+	ignored := func(name string, entry entry, dirs map[string]struct{}) bool {
+		_, ok := dirs[name]
+		return entry.typ == fs.ModeDir && !ok && !o.emptyDirs
+	}
+
+	// check all expected entries against actual entries
+	for _, name := range sortedKeys(expectedEntries) {
+		expectedEntry := expectedEntries[name]
+		actualEntry, ok := actualEntries[name]
 		if !ok {
+			assert.True(t, ignored(name, expectedEntry, expectedDirs), "%s missing from actual directory", name)
+			continue
+		}
+
+		if expectedEntry.typ != actualEntry.typ {
+			assert.Fail(t, fmt.Sprintf("%s is %s in actual directory but %s in expected one",
+				name, typeName(actualEntry.typ), typeName(expectedEntry.typ)))
 			continue
 		}
 
-		diffs := Diff(filename, expectedBytes, filename, actualBytes)
+		if o.modes && expectedEntry.perm != actualEntry.perm {
+			assert.Fail(t, fmt.Sprintf("%s: mode %#o, want %#o", name, actualEntry.perm, expectedEntry.perm))
+		}
+
+		if expectedEntry.typ == fs.ModeSymlink && o.symlinks {
+			if expectedEntry.target != actualEntry.target {
+				assert.Fail(t, fmt.Sprintf("%s: target %s, want %s", name, actualEntry.target, expectedEntry.target))
+			}
+			continue
+		}
+
+		diffs := o.diff(name, expectedEntry.content, name, actualEntry.content)
 		if len(diffs) > 0 {
-			assert.Fail(t, filename+" is different from expected", string(diffs))
+			assert.Fail(t, name+" is different from expected", string(diffs))
 		}
 	}
 
-	// check that there're no actual files that aren't present in expected files
-	for filename := range actualFiles {
-		_, ok := expectedFiles[filename]
-		assert.True(t, ok, "%s is present in actual directory but not in expected one", filename)
+	// check that there're no actual entries that aren't present in expected entries
+	for _, name := range sortedKeys(actualEntries) {
+		if _, ok := expectedEntries[name]; !ok {
+			assert.True(t, ignored(name, actualEntries[name], actualDirs), "%s is present in actual directory but not in expected one", name)
+		}
 	}
 }
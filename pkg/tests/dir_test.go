@@ -0,0 +1,105 @@
+package tests_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+// fakeT records failures and logs reported by assertions instead of failing the running test.
+type fakeT struct {
+	testing.TB
+
+	errors []string
+	logs   []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Logf(format string, args ...any) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+// failures returns all recorded failures joined by new lines.
+func (f *fakeT) failures() string {
+	return strings.Join(f.errors, "\n")
+}
+
+// writeFiles writes all files (relative slash separated paths with their content) into dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		dest := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(dest), filesystem.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(dest, []byte(content), filesystem.RwRR))
+	}
+}
+
+func TestAssertEqualDir(t *testing.T) {
+	t.Setenv(tests.UpdateEnv, "") // differences must be reported even when golden files are being updated
+
+	t.Run("error_swapped_paths", func(t *testing.T) {
+		// Arrange
+		expected := t.TempDir()
+		writeFiles(t, expected, map[string]string{"a/x.txt": "a\n", "b/x.txt": "b\n"})
+		actual := t.TempDir()
+		writeFiles(t, actual, map[string]string{"a/x.txt": "b\n", "b/x.txt": "a\n"})
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		require.Len(t, fake.errors, 2)
+		assert.Contains(t, fake.errors[0], "a/x.txt is different from expected")
+		assert.Contains(t, fake.errors[0], "--- a/x.txt")
+		assert.Contains(t, fake.errors[1], "b/x.txt is different from expected")
+		// expected content is the old side of the diff, actual content the new one
+		assert.Regexp(t, `(?s)--- a/x\.txt.*\+\+\+ a/x\.txt.*-a.*\+b`, fake.errors[0])
+		assert.Regexp(t, `(?s)--- b/x\.txt.*\+\+\+ b/x\.txt.*-b.*\+a`, fake.errors[1])
+	})
+
+	t.Run("error_type_mismatch", func(t *testing.T) {
+		// Arrange
+		expected := t.TempDir()
+		writeFiles(t, expected, map[string]string{"a/x.txt": "a\n"})
+		actual := t.TempDir()
+		writeFiles(t, actual, map[string]string{"a": "a\n"})
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Contains(t, fake.failures(), "a is a file in actual directory but a directory in expected one")
+		assert.Contains(t, fake.failures(), "a/x.txt missing from actual directory")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		files := map[string]string{"a/x.txt": "a\n", "b/x.txt": "b\n", "x.txt": "x\n"}
+		expected := t.TempDir()
+		writeFiles(t, expected, files)
+		actual := t.TempDir()
+		writeFiles(t, actual, files)
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
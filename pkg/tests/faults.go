@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+// ErrInjected is the default error returned by a Fault when no other effect is specified.
+var ErrInjected = errors.New("injected fault")
+
+// FaultOp represents a filesystem operation on which a Fault can be injected.
+type FaultOp string
+
+const (
+	// FaultOpen is the FS Open operation.
+	FaultOpen FaultOp = "open"
+	// FaultRead is the Read operation on files returned by FS Open.
+	FaultRead FaultOp = "read"
+	// FaultReadDir is the FS ReadDir operation (and ReadDir on directories returned by FS Open).
+	FaultReadDir FaultOp = "readdir"
+	// FaultReadFile is the FS ReadFile operation.
+	FaultReadFile FaultOp = "readfile"
+	// FaultStat is the FS Stat operation (and Stat on files returned by FS Open).
+	FaultStat FaultOp = "stat"
+	// FaultLstat is the FS Lstat operation.
+	FaultLstat FaultOp = "lstat"
+	// FaultReadLink is the FS ReadLink operation.
+	FaultReadLink FaultOp = "readlink"
+)
+
+// Fault represents a fault injected by a FaultFS on an operation for paths matching a pattern.
+type Fault struct {
+	op      FaultOp
+	pattern string
+
+	nth       int
+	err       error
+	shortRead int
+	delay     time.Duration
+
+	calls int
+}
+
+// FaultOption represents a function to customize a Fault.
+type FaultOption func(f *Fault)
+
+// WithNth specifies that the fault must only be injected on the nth (starting at 1) matching call.
+//
+// By default, the fault is injected on all matching calls.
+func WithNth(n int) FaultOption {
+	return func(f *Fault) {
+		f.nth = n
+	}
+}
+
+// WithError specifies the error returned (wrapped in a *fs.PathError) by the faulty operation.
+func WithError(err error) FaultOption {
+	return func(f *Fault) {
+		f.err = err
+	}
+}
+
+// WithShortRead specifies that the faulty operation must return at most n bytes.
+//
+// It only applies on FaultRead (each Read call returns at most n bytes) and FaultReadFile (content is truncated) operations.
+func WithShortRead(n int) FaultOption {
+	return func(f *Fault) {
+		f.shortRead = n
+	}
+}
+
+// WithDelay specifies that the faulty operation must wait for the given duration before being executed.
+func WithDelay(delay time.Duration) FaultOption {
+	return func(f *Fault) {
+		f.delay = delay
+	}
+}
+
+// Inject returns a new Fault for op on all paths matching pattern (see filesystem.Match for syntax).
+//
+// Paths are matched slash separated, as given to FaultFS. When no effect is given (WithError, WithShortRead, WithDelay),
+// the faulty operation fails with ErrInjected.
+func Inject(op FaultOp, pattern string, opts ...FaultOption) *Fault {
+	f := &Fault{op: op, pattern: pattern}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(f)
+		}
+	}
+	if f.err == nil && f.shortRead <= 0 && f.delay <= 0 {
+		f.err = ErrInjected
+	}
+	return f
+}
+
+// FaultFS is a filesystem.FS wrapping another one and injecting faults (errors, short reads, delays) on chosen operations.
+//
+// It's useful to deterministically test error paths without playing with permissions in temporary directories.
+type FaultFS struct {
+	fsys   filesystem.FS
+	faults []*Fault
+	mutex  sync.Mutex
+}
+
+var _ filesystem.FS = (*FaultFS)(nil) // ensure interface is implemented
+
+// NewFaultFS returns a new FaultFS wrapping fsys and injecting given faults.
+//
+// When multiple faults apply on the same call, only the first given one is injected.
+func NewFaultFS(fsys filesystem.FS, faults ...*Fault) *FaultFS {
+	return &FaultFS{fsys: fsys, faults: faults}
+}
+
+// inject returns the fault to inject for op on name, nil if none.
+//
+// Calls are counted on all matching faults, even when another one is injected.
+func (f *FaultFS) inject(op FaultOp, name string) *Fault {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	name = filepath.ToSlash(name)
+	var injected *Fault
+	for _, fault := range f.faults {
+		if fault.op != op {
+			continue
+		}
+		if ok, _ := filesystem.Match(fault.pattern, name); !ok {
+			continue
+		}
+		fault.calls++
+		if injected == nil && (fault.nth <= 0 || fault.calls == fault.nth) {
+			injected = fault
+		}
+	}
+	return injected
+}
+
+// apply waits for fault delay and returns fault error (wrapped in a *fs.PathError) if any.
+func (fault *Fault) apply(name string) error {
+	if fault == nil {
+		return nil
+	}
+	if fault.delay > 0 {
+		time.Sleep(fault.delay)
+	}
+	if fault.err != nil {
+		return &fs.PathError{Op: string(fault.op), Path: name, Err: fault.err}
+	}
+	return nil
+}
+
+// Open opens the named file for reading, files being wrapped to inject faults on Read, ReadDir and Stat.
+func (f *FaultFS) Open(name string) (fs.File, error) {
+	if err := f.inject(FaultOpen, name).apply(name); err != nil {
+		return nil, err
+	}
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &faultFile{File: file, fsys: f, name: name}, nil
+}
+
+// ReadDir reads the named directory and returns all its directory entries sorted by filename.
+func (f *FaultFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := f.inject(FaultReadDir, name).apply(name); err != nil {
+		return nil, err
+	}
+	return f.fsys.ReadDir(name)
+}
+
+// ReadFile reads the named file and returns its contents.
+func (f *FaultFS) ReadFile(name string) ([]byte, error) {
+	fault := f.inject(FaultReadFile, name)
+	if err := fault.apply(name); err != nil {
+		return nil, err
+	}
+	content, err := f.fsys.ReadFile(name)
+	if fault != nil && fault.shortRead > 0 {
+		content = content[:min(len(content), fault.shortRead)]
+	}
+	return content, err
+}
+
+// Stat returns a FileInfo describing the named file.
+func (f *FaultFS) Stat(name string) (fs.FileInfo, error) {
+	if err := f.inject(FaultStat, name).apply(name); err != nil {
+		return nil, err
+	}
+	return fs.Stat(f.fsys, name)
+}
+
+// Lstat returns a FileInfo describing the named file without following symbolic links.
+//
+// When wrapped filesystem doesn't support symbolic links, name is looked up in its parent directory entries.
+func (f *FaultFS) Lstat(name string) (fs.FileInfo, error) {
+	if err := f.inject(FaultLstat, name).apply(name); err != nil {
+		return nil, err
+	}
+	if fsys, ok := f.fsys.(interface {
+		Lstat(name string) (fs.FileInfo, error)
+	}); ok {
+		return fsys.Lstat(name)
+	}
+
+	entries, err := f.fsys.ReadDir(path.Dir(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: err}
+	}
+	for _, entry := range entries {
+		if entry.Name() == path.Base(name) {
+			return entry.Info()
+		}
+	}
+	return nil, &fs.PathError{Op: "lstat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadLink returns the destination of the named symbolic link.
+//
+// It fails with errors.ErrUnsupported when wrapped filesystem doesn't support symbolic links.
+func (f *FaultFS) ReadLink(name string) (string, error) {
+	if err := f.inject(FaultReadLink, name).apply(name); err != nil {
+		return "", err
+	}
+	if fsys, ok := f.fsys.(interface {
+		ReadLink(name string) (string, error)
+	}); ok {
+		return fsys.ReadLink(name)
+	}
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: errors.ErrUnsupported}
+}
+
+// faultFile wraps a file opened by FaultFS to inject faults on Read, ReadDir and Stat.
+type faultFile struct {
+	fs.File
+	fsys *FaultFS
+	name string
+}
+
+var _ fs.ReadDirFile = (*faultFile)(nil) // ensure interface is implemented
+
+// Read reads up to len(p) bytes into p.
+func (f *faultFile) Read(p []byte) (int, error) {
+	fault := f.fsys.inject(FaultRead, f.name)
+	if err := fault.apply(f.name); err != nil {
+		return 0, err
+	}
+	if fault != nil && fault.shortRead > 0 {
+		p = p[:min(len(p), fault.shortRead)]
+	}
+	return f.File.Read(p)
+}
+
+// ReadDir reads the contents of the directory and returns a slice of up to n entries.
+func (f *faultFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if err := f.fsys.inject(FaultReadDir, f.name).apply(f.name); err != nil {
+		return nil, err
+	}
+	dir, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: errors.ErrUnsupported}
+	}
+	return dir.ReadDir(n)
+}
+
+// Stat returns a FileInfo describing the file.
+func (f *faultFile) Stat() (fs.FileInfo, error) {
+	if err := f.fsys.inject(FaultStat, f.name).apply(f.name); err != nil {
+		return nil, err
+	}
+	return f.File.Stat()
+}
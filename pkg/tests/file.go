@@ -1,6 +1,9 @@
 package tests
 
 import (
+	"bytes"
+	"errors"
+	"io/fs"
 	"os"
 	"testing"
 
@@ -21,15 +24,47 @@ func FilterCarriage(bytes []byte) []byte {
 // AssertEqualFile compares expected and actual files.
 //
 // It will fail with t if one of the file cannot be read or if their content is not identical.
-func AssertEqualFile(t testing.TB, expected, actual string) {
+// Binary contents (see IsBinary) are reported with BinaryDiff instead of a unified diff.
+//
+// Options can be given to normalize contents before comparison (WithTransform, WithReplace, WithTrimTrailingSpace).
+//
+// In update mode (see Updating), expected file is overwritten with actual file instead.
+func AssertEqualFile(t testing.TB, expected, actual string, opts ...AssertOption) {
+	o := newAssertOpt(opts...)
+
+	// expected file may not exist yet in update mode
 	expectedBytes, err := os.ReadFile(expected)
-	assert.NoError(t, err, "failed to read %s", expected)
+	exists := !errors.Is(err, fs.ErrNotExist)
+	if !Updating() || exists {
+		assert.NoError(t, err, "failed to read %s", expected)
+	}
 
 	actualBytes, err := os.ReadFile(actual)
 	assert.NoError(t, err, "failed to read %s", actual)
 
-	diffs := Diff(expected, FilterCarriage(expectedBytes), actual, FilterCarriage(actualBytes))
+	if Updating() {
+		if exists && bytes.Equal(o.normalize(expectedBytes), o.normalize(actualBytes)) {
+			return
+		}
+		if err := updateFile(expected, actual); err != nil {
+			assert.NoError(t, err, "failed to update %s", expected)
+			return
+		}
+		if exists {
+			t.Logf("golden update: updated %s", expected)
+		} else {
+			t.Logf("golden update: created %s", expected)
+		}
+		return
+	}
+
+	assertEqualContent(t, expected, expectedBytes, actual, actualBytes, o)
+}
+
+// assertEqualContent compares expected and actual contents and fails with t if they're not identical.
+func assertEqualContent(t testing.TB, expectedName string, expected []byte, actualName string, actual []byte, o *assertOpt) {
+	diffs := o.diff(expectedName, expected, actualName, actual)
 	if len(diffs) > 0 {
-		assert.Fail(t, actual+" is different from expected", string(diffs))
+		assert.Fail(t, actualName+" is different from expected", string(diffs))
 	}
 }
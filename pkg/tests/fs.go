@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readLinkFS represents a filesystem able to read symbolic links destination (ReadLink part of fs.ReadLinkFS available since go1.25).
+type readLinkFS interface {
+	fs.FS
+
+	// ReadLink returns the destination of the named symbolic link.
+	ReadLink(name string) (string, error)
+}
+
+// dirFS is the filesystem of an OS directory (see os.DirFS) supporting symbolic links.
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+var _ readLinkFS = (*dirFS)(nil) // ensure interface is implemented
+
+// newDirFS returns the filesystem of dir.
+func newDirFS(dir string) *dirFS {
+	return &dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+// ReadLink returns the destination of the named symbolic link.
+func (d *dirFS) ReadLink(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.Readlink(filepath.Join(d.dir, filepath.FromSlash(name)))
+}
+
+// AssertEqualFS compares expected and actual filesystems (from their root), e.g. an embed.FS of expected outputs
+// against a fstest.MapFS of generated ones.
+//
+// It behaves like AssertEqualDir with the same options, symbolic links targets being only available
+// when filesystems implement a ReadLink(name string) (string, error) function.
+// Since filesystems may be read-only, expected filesystem isn't updated in update mode (see Updating).
+func AssertEqualFS(t testing.TB, expected, actual fs.FS, opts ...AssertOption) {
+	o := newAssertOpt(opts...)
+
+	expectedEntries, err := readFSInMap(expected)
+	assert.NoError(t, err, "failed to completely read expected filesystem")
+	expectedEntries = o.filter(expectedEntries)
+
+	actualEntries, err := readFSInMap(actual)
+	assert.NoError(t, err, "failed to completely read actual filesystem")
+	actualEntries = o.filter(actualEntries)
+
+	assertEqualEntries(t, expectedEntries, actualEntries, o)
+}
+
+// AssertEqualFileFS compares expectedName file from expected filesystem and actualName file from actual filesystem.
+//
+// It behaves like AssertEqualFile with the same options.
+// Since filesystems may be read-only, expected file isn't updated in update mode (see Updating).
+func AssertEqualFileFS(t testing.TB, expected fs.FS, expectedName string, actual fs.FS, actualName string, opts ...AssertOption) {
+	o := newAssertOpt(opts...)
+
+	expectedBytes, err := fs.ReadFile(expected, expectedName)
+	assert.NoError(t, err, "failed to read %s", expectedName)
+
+	actualBytes, err := fs.ReadFile(actual, actualName)
+	assert.NoError(t, err, "failed to read %s", actualName)
+
+	assertEqualContent(t, expectedName, expectedBytes, actualName, actualBytes, o)
+}
@@ -0,0 +1,98 @@
+package tests_test
+
+import (
+	"io/fs"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestAssertEqualFS(t *testing.T) {
+	expected := fstest.MapFS{
+		"file.txt":     {Data: []byte("file\n")},
+		"dir/file.txt": {Data: []byte("nested\n")},
+		"empty":        {Mode: fs.ModeDir},
+	}
+
+	t.Run("error_different", func(t *testing.T) {
+		// Arrange
+		actual := fstest.MapFS{
+			"file.txt":       {Data: []byte("file\n")},
+			"dir/file.txt":   {Data: []byte("other\n")},
+			"dir/extra.txt":  {Data: []byte("extra\n")},
+			"other/file.txt": {Data: []byte("file\n")},
+		}
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFS(fake, expected, actual, tests.WithEmptyDirs())
+
+		// Assert
+		assert.Contains(t, fake.failures(), "dir/file.txt is different from expected")
+		assert.Contains(t, fake.failures(), "-nested")
+		assert.Contains(t, fake.failures(), "+other")
+		assert.Contains(t, fake.failures(), "empty missing from actual directory")
+		assert.Contains(t, fake.failures(), "dir/extra.txt is present in actual directory but not in expected one")
+		assert.Contains(t, fake.failures(), "other/file.txt is present in actual directory but not in expected one")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		actual := fstest.MapFS{
+			"file.txt":     {Data: []byte("file\r\n")},
+			"dir/file.txt": {Data: []byte("nested\n")},
+		}
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFS(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
+
+func TestAssertEqualFileFS(t *testing.T) {
+	expected := fstest.MapFS{"expected.txt": {Data: []byte("hey file\n")}}
+
+	t.Run("error_not_exists", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFileFS(fake, expected, "expected.txt", fstest.MapFS{}, "invalid.txt")
+
+		// Assert
+		assert.Contains(t, fake.failures(), "failed to read invalid.txt")
+	})
+
+	t.Run("error_different", func(t *testing.T) {
+		// Arrange
+		actual := fstest.MapFS{"actual.txt": {Data: []byte("hey other\n")}}
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFileFS(fake, expected, "expected.txt", actual, "actual.txt")
+
+		// Assert
+		assert.Contains(t, fake.failures(), "actual.txt is different from expected")
+		assert.Contains(t, fake.failures(), "--- expected.txt")
+		assert.Contains(t, fake.failures(), "+++ actual.txt")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		actual := fstest.MapFS{"actual.txt": {Data: []byte("hey FILE\n")}}
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFileFS(fake, expected, "expected.txt", actual, "actual.txt", tests.WithReplace(regexp.MustCompile("(?i)file"), "file"))
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
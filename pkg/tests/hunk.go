@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// noEOL is the message attached by lines to the last line of a text not ending with a newline.
+const noEOL = "\\ No newline at end of file"
+
+// LineOp represents the operation of a DiffLine.
+type LineOp string
+
+const (
+	// LineEqual is an unchanged (context) line, present in both old and new texts.
+	LineEqual LineOp = "equal"
+	// LineDelete is a line only present in old text.
+	LineDelete LineOp = "delete"
+	// LineInsert is a line only present in new text.
+	LineInsert LineOp = "insert"
+)
+
+// prefix returns the unified diff prefix of op.
+func (op LineOp) prefix() string {
+	switch op {
+	case LineDelete:
+		return "-"
+	case LineInsert:
+		return "+"
+	default:
+		return " "
+	}
+}
+
+// DiffLine represents a single line of a Hunk.
+type DiffLine struct {
+	// Op is the operation of the line.
+	Op LineOp `json:"op"`
+
+	// Content is the line content, without its trailing newline.
+	Content string `json:"content"`
+
+	// NoEOL is true when the line is the last line of its text and isn't terminated by a newline.
+	NoEOL bool `json:"noEOL,omitempty"`
+}
+
+// Hunk represents a group of changed lines with their surrounding context lines.
+//
+// Start lines are 1-indexed, except for an empty range where start is the line preceding the range (0 for an empty text).
+type Hunk struct {
+	OldStart int        `json:"oldStart"`
+	OldLines int        `json:"oldLines"`
+	NewStart int        `json:"newStart"`
+	NewLines int        `json:"newLines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// FileDiff represents the differences between two named texts, as returned in JSON by DiffJSON.
+type FileDiff struct {
+	OldName string `json:"oldName"`
+	NewName string `json:"newName"`
+	Hunks   []Hunk `json:"hunks"`
+}
+
+// diffLine returns the DiffLine of line s (as returned by lines) with op.
+func diffLine(op LineOp, s string) DiffLine {
+	s = strings.TrimSuffix(s, "\n")
+	content, noNewline := strings.CutSuffix(s, "\n"+noEOL)
+	return DiffLine{Op: op, Content: content, NoEOL: noNewline}
+}
+
+// FormatHunks returns the given hunks in the “unified diff” format, with oldName and newName as files header.
+func FormatHunks(oldName, newName string, hunks []Hunk) []byte {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n", oldName)
+	fmt.Fprintf(&out, "+++ %s\n", newName)
+	for _, hunk := range hunks {
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+		for _, line := range hunk.Lines {
+			out.WriteString(line.Op.prefix())
+			out.WriteString(line.Content)
+			out.WriteByte('\n')
+			if line.NoEOL {
+				out.WriteString(noEOL + "\n")
+			}
+		}
+	}
+	return out.Bytes()
+}
+
+// DiffJSON returns the differences between old and new texts (see Diff for algorithms and options) as a JSON FileDiff.
+//
+// Unlike Diff, it returns a FileDiff without hunks when old and new are identical.
+func DiffJSON(oldName string, old []byte, newName string, new []byte, opts ...DiffOption) ([]byte, error) {
+	diff := FileDiff{OldName: oldName, NewName: newName, Hunks: Hunks(old, new, opts...)}
+	if diff.Hunks == nil {
+		diff.Hunks = []Hunk{}
+	}
+	content, err := json.Marshal(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal diff: %w", err)
+	}
+	return content, nil
+}
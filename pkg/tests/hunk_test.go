@@ -0,0 +1,129 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestHunks(t *testing.T) {
+	t.Run("success_equal", func(t *testing.T) {
+		// Act
+		hunks := tests.Hunks(text("1 2"), text("1 2"))
+
+		// Assert
+		assert.Nil(t, hunks)
+	})
+
+	t.Run("success_ranges", func(t *testing.T) {
+		// Act
+		hunks := tests.Hunks(text("1 2 3 4 5 6 7 8 9"), text("1 2 x 4 5 6 7 8 9 10"), tests.WithContext(1))
+
+		// Assert
+		assert.Equal(t, []tests.Hunk{
+			{
+				OldStart: 2, OldLines: 3, NewStart: 2, NewLines: 3,
+				Lines: []tests.DiffLine{
+					{Op: tests.LineEqual, Content: "2"},
+					{Op: tests.LineDelete, Content: "3"},
+					{Op: tests.LineInsert, Content: "x"},
+					{Op: tests.LineEqual, Content: "4"},
+				},
+			},
+			{
+				OldStart: 9, OldLines: 1, NewStart: 9, NewLines: 2,
+				Lines: []tests.DiffLine{
+					{Op: tests.LineEqual, Content: "9"},
+					{Op: tests.LineInsert, Content: "10"},
+				},
+			},
+		}, hunks)
+	})
+
+	t.Run("success_empty_range", func(t *testing.T) {
+		// Act
+		hunks := tests.Hunks(nil, text("1"))
+
+		// Assert
+		assert.Equal(t, []tests.Hunk{{
+			OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1,
+			Lines: []tests.DiffLine{{Op: tests.LineInsert, Content: "1"}},
+		}}, hunks)
+	})
+
+	t.Run("success_no_eol", func(t *testing.T) {
+		// Act
+		hunks := tests.Hunks([]byte("1\n2"), []byte("1\n2\n"))
+
+		// Assert
+		assert.Equal(t, []tests.Hunk{{
+			OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2,
+			Lines: []tests.DiffLine{
+				{Op: tests.LineEqual, Content: "1"},
+				{Op: tests.LineDelete, Content: "2", NoEOL: true},
+				{Op: tests.LineInsert, Content: "2"},
+			},
+		}}, hunks)
+	})
+}
+
+func TestFormatHunks(t *testing.T) {
+	cases := []struct {
+		name string
+		old  []byte
+		new  []byte
+	}{
+		{name: "insert", old: nil, new: text("1 2")},
+		{name: "delete", old: text("1 2"), new: nil},
+		{name: "multiple_hunks", old: text("1 2 3 4 5 6 7 8 9 10 11 12"), new: text("x 2 3 4 5 6 7 8 9 10 11 y")},
+		{name: "no_eol", old: []byte("1\n2"), new: []byte("1\n3")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Act
+			formatted := tests.FormatHunks("a", "b", tests.Hunks(c.old, c.new))
+
+			// Assert
+			assert.Equal(t, string(tests.Diff("a", c.old, "b", c.new)), "diff a b\n"+string(formatted))
+		})
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	t.Run("success_equal", func(t *testing.T) {
+		// Act
+		content, err := tests.DiffJSON("a", text("1"), "b", text("1"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"oldName":"a","newName":"b","hunks":[]}`, string(content))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		content, err := tests.DiffJSON("a", []byte("1\n2"), "b", []byte("1\n3\n"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"oldName": "a",
+			"newName": "b",
+			"hunks": [{
+				"oldStart": 1, "oldLines": 2, "newStart": 1, "newLines": 2,
+				"lines": [
+					{"op": "equal", "content": "1"},
+					{"op": "delete", "content": "2", "noEOL": true},
+					{"op": "insert", "content": "3"}
+				]
+			}]
+		}`, string(content))
+
+		var diff tests.FileDiff
+		require.NoError(t, json.Unmarshal(content, &diff))
+		assert.Equal(t, tests.Hunks([]byte("1\n2"), []byte("1\n3\n")), diff.Hunks)
+	})
+}
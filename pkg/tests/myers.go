@@ -0,0 +1,77 @@
+package tests
+
+import "slices"
+
+// myers returns the pairs of indexes starting each run of matching lines (snake) in x and y
+// of a shortest edit script found with the Myers diff algorithm.
+//
+// Like tgs, a leading {0,0} and trailing {len(x), len(y)} pair are added to the returned sequence.
+// Since snakes are always extended as far as possible, the matching lines following each returned pair
+// can be found by scanning forward until x and y differ.
+//
+// The algorithm is as described in Eugene W. Myers, “An O(ND) Difference Algorithm and Its Variations,”
+// Algorithmica 1 (1986), available at http://www.xmailserver.org/diff2.pdf.
+func myers(x, y []string) []pair {
+	n, m := len(x), len(y)
+	offset := n + m + 1
+
+	// v[offset+k] is the furthest x reached on diagonal k (x - y = k),
+	// trace[d] keeps v diagonals [-d, d] as they were before step d to walk back the edit script
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	d := 0
+search:
+	for ; ; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1] // line inserted from y
+			} else {
+				i = v[offset+k-1] + 1 // line removed from x
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back the edit script from the end, keeping the start of each snake
+	var snakes []pair
+	end := pair{n, m}
+	for ; d > 0; d-- {
+		prev := trace[d] // diagonals reached after step d-1, diagonal k being at index k+d
+		k := end.x - end.y
+
+		// from is the point reached after step d-1, mid the point after step d edit (and before its snake)
+		var from, mid pair
+		if k == -d || (k != d && prev[k-1+d] < prev[k+1+d]) {
+			from = pair{prev[k+1+d], prev[k+1+d] - (k + 1)}
+			mid = pair{from.x, from.y + 1}
+		} else {
+			from = pair{prev[k-1+d], prev[k-1+d] - (k - 1)}
+			mid = pair{from.x + 1, from.y}
+		}
+		if mid.x < end.x {
+			snakes = append(snakes, mid)
+		}
+		end = from
+	}
+	if end.x > 0 {
+		snakes = append(snakes, pair{0, 0}) // snake before any edit
+	}
+
+	seq := make([]pair, 0, len(snakes)+2)
+	seq = append(seq, pair{0, 0}) // sentinel at start
+	for i := len(snakes) - 1; i >= 0; i-- {
+		seq = append(seq, snakes[i])
+	}
+	return append(seq, pair{n, m}) // sentinel at end
+}
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+// AssertOption represents a function to customize comparisons made by AssertEqualFile and AssertEqualDir.
+type AssertOption func(o *assertOpt)
+
+// Transform represents a function normalizing a file content before comparison.
+type Transform func(content []byte) []byte
+
+// WithTransform specifies transformations applied (in given order) on both expected and actual contents before comparison.
+func WithTransform(transforms ...Transform) AssertOption {
+	return func(o *assertOpt) {
+		for _, transform := range transforms {
+			if transform != nil {
+				o.transforms = append(o.transforms, transform)
+			}
+		}
+	}
+}
+
+// WithReplace specifies that all matches of re must be replaced by repl (see regexp.Regexp ReplaceAll)
+// in both expected and actual contents before comparison.
+//
+// It's useful to ignore timestamps, versions or temporary paths.
+func WithReplace(re *regexp.Regexp, repl string) AssertOption {
+	return WithTransform(func(content []byte) []byte {
+		return re.ReplaceAll(content, []byte(repl))
+	})
+}
+
+// WithTrimTrailingSpace specifies that trailing spaces and tabs of each line
+// must be removed in both expected and actual contents before comparison.
+func WithTrimTrailingSpace() AssertOption {
+	return WithTransform(func(content []byte) []byte {
+		lines := bytes.Split(content, []byte("\n"))
+		for i, line := range lines {
+			lines[i] = bytes.TrimRight(line, " \t")
+		}
+		return bytes.Join(lines, []byte("\n"))
+	})
+}
+
+// WithIgnore specifies patterns (see filesystem.Match for syntax) of relative paths to ignore in AssertEqualDir.
+//
+// When a directory is ignored, its whole content is ignored too.
+// Ignored paths are neither compared nor updated in update mode.
+func WithIgnore(patterns ...string) AssertOption {
+	return func(o *assertOpt) {
+		o.ignores = append(o.ignores, patterns...)
+	}
+}
+
+// WithModes specifies that permission bits of files and directories must be compared.
+func WithModes() AssertOption {
+	return func(o *assertOpt) {
+		o.modes = true
+	}
+}
+
+// WithSymlinkTargets specifies that symbolic links must be compared by target
+// instead of by their target content.
+func WithSymlinkTargets() AssertOption {
+	return func(o *assertOpt) {
+		o.symlinks = true
+	}
+}
+
+// WithEmptyDirs specifies that empty directories must be compared,
+// by default only directories with content are compared (through their content).
+func WithEmptyDirs() AssertOption {
+	return func(o *assertOpt) {
+		o.emptyDirs = true
+	}
+}
+
+// WithDiffOptions specifies options given to Diff when reporting differences between textual contents.
+func WithDiffOptions(opts ...DiffOption) AssertOption {
+	return func(o *assertOpt) {
+		o.diffOpts = append(o.diffOpts, opts...)
+	}
+}
+
+// WithRenderer specifies the renderer used to report differences between textual contents
+// (e.g. Intraline or SideBySide), by default differences are reported in the “unified diff” format (see Diff).
+func WithRenderer(renderer Renderer) AssertOption {
+	return func(o *assertOpt) {
+		o.renderer = renderer
+	}
+}
+
+type assertOpt struct {
+	modes     bool
+	symlinks  bool
+	emptyDirs bool
+
+	ignores    []string
+	transforms []Transform
+	diffOpts   []DiffOption
+	renderer   Renderer
+}
+
+func newAssertOpt(opts ...AssertOption) *assertOpt {
+	o := &assertOpt{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// normalize returns content without carriage returns and with all transformations applied.
+//
+// Binary content (see IsBinary) is returned untouched.
+func (o *assertOpt) normalize(content []byte) []byte {
+	if IsBinary(content) {
+		return content
+	}
+	content = FilterCarriage(content)
+	for _, transform := range o.transforms {
+		content = transform(content)
+	}
+	return content
+}
+
+// diff returns the differences between normalized old and new contents (nil when identical).
+//
+// Binary contents are reported with BinaryDiff, textual ones with Diff or the renderer given with WithRenderer.
+func (o *assertOpt) diff(oldName string, old []byte, newName string, new []byte) []byte {
+	old, new = o.normalize(old), o.normalize(new)
+	if IsBinary(old) || IsBinary(new) {
+		return BinaryDiff(oldName, old, newName, new)
+	}
+	if o.renderer == nil {
+		return Diff(oldName, old, newName, new, o.diffOpts...)
+	}
+
+	hunks := Hunks(old, new, o.diffOpts...)
+	if len(hunks) == 0 {
+		return nil
+	}
+	return append([]byte("diff "+oldName+" "+newName+"\n"), o.renderer(oldName, newName, hunks)...)
+}
+
+// ignored returns true if name (a slash separated relative path) or one of its parent directories is ignored.
+func (o *assertOpt) ignored(name string) bool {
+	for dir := name; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		for _, pattern := range o.ignores {
+			if ok, _ := filesystem.Match(pattern, dir); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filter returns entries without ignored ones.
+func (o *assertOpt) filter(entries map[string]entry) map[string]entry {
+	if len(o.ignores) == 0 {
+		return entries
+	}
+	kept := make(map[string]entry, len(entries))
+	for name, entry := range entries {
+		if !o.ignored(name) {
+			kept[name] = entry
+		}
+	}
+	return kept
+}
+
+// Algorithm represents a diff algorithm used to compute the differences between two texts.
+type Algorithm int
+
+const (
+	// AlgorithmAnchored is the anchored diff algorithm, anchoring matching regions on unique lines (see Diff).
+	AlgorithmAnchored Algorithm = iota
+	// AlgorithmMyers is the Myers diff algorithm, looking for the smallest number of lines inserted and removed.
+	AlgorithmMyers
+)
+
+// DiffOption represents a function to customize Diff.
+type DiffOption func(o *diffOpt)
+
+// WithContext specifies the number of unchanged lines printed around changes (3 by default).
+func WithContext(lines int) DiffOption {
+	return func(o *diffOpt) {
+		o.context = max(lines, 0)
+	}
+}
+
+// WithAlgorithm specifies the algorithm used to compute the differences (AlgorithmAnchored by default).
+func WithAlgorithm(algorithm Algorithm) DiffOption {
+	return func(o *diffOpt) {
+		o.algorithm = algorithm
+	}
+}
+
+type diffOpt struct {
+	algorithm Algorithm
+	context   int
+}
+
+func newDiffOpt(opts ...DiffOption) *diffOpt {
+	o := &diffOpt{context: 3}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// matches returns the pairs of indexes of matching lines in x and y according to the algorithm,
+// with a leading {0,0} and trailing {len(x), len(y)} pair.
+func (o *diffOpt) matches(x, y []string) []pair {
+	if o.algorithm == AlgorithmMyers {
+		return myers(x, y)
+	}
+	return tgs(x, y)
+}
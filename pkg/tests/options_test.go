@@ -0,0 +1,251 @@
+package tests_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+// assertFiles writes expected and actual contents in temporary files and compares them with AssertEqualFile,
+// returning the recorded failures.
+func assertFiles(t *testing.T, expected, actual string, opts ...tests.AssertOption) []string {
+	t.Helper()
+	t.Setenv(tests.UpdateEnv, "")
+
+	tmp := t.TempDir()
+	writeFiles(t, tmp, map[string]string{"expected.txt": expected, "actual.txt": actual})
+	fake := &fakeT{TB: t}
+	tests.AssertEqualFile(fake, filepath.Join(tmp, "expected.txt"), filepath.Join(tmp, "actual.txt"), opts...)
+	return fake.errors
+}
+
+func TestWithTransform(t *testing.T) {
+	upper := func(content []byte) []byte { return bytes.ToUpper(content) }
+
+	t.Run("error_without", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "hello\n", "HELLO\n")
+
+		// Assert
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "hello\n", "HELLO\n", tests.WithTransform(upper))
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+
+	t.Run("success_in_order", func(t *testing.T) {
+		// Arrange
+		trim := func(content []byte) []byte { return bytes.TrimPrefix(content, []byte("HEADER ")) }
+
+		// Act
+		errs := assertFiles(t, "header hello\n", "HELLO\n", tests.WithTransform(upper, trim))
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+}
+
+func TestWithReplace(t *testing.T) {
+	version := regexp.MustCompile(`v\d+\.\d+\.\d+`)
+
+	t.Run("error_without", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "generated by v1.0.0\n", "generated by v1.2.3\n")
+
+		// Assert
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("error_other_difference", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "generated by v1.0.0\n", "created by v1.2.3\n", tests.WithReplace(version, "vX"))
+
+		// Assert
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "+created by vX")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "generated by v1.0.0\n", "generated by v1.2.3\n", tests.WithReplace(version, "vX"))
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+}
+
+func TestWithTrimTrailingSpace(t *testing.T) {
+	t.Run("error_without", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "a\nb\n", "a \t\nb\n")
+
+		// Assert
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("error_leading_space", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "a\nb\n", " a\nb\n", tests.WithTrimTrailingSpace())
+
+		// Assert
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		errs := assertFiles(t, "a\nb\n", "a \t\nb  \n", tests.WithTrimTrailingSpace())
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+}
+
+func TestWithIgnore(t *testing.T) {
+	t.Setenv(tests.UpdateEnv, "")
+
+	expected := t.TempDir()
+	writeFiles(t, expected, map[string]string{"file.txt": "file", "build/out.bin": "old", "logs/a.log": "a"})
+	actual := t.TempDir()
+	writeFiles(t, actual, map[string]string{"file.txt": "file", "build/out.bin": "new", "b.log": "b"})
+
+	t.Run("error_without", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Contains(t, fake.failures(), "build/out.bin is different from expected")
+		assert.Contains(t, fake.failures(), "logs/a.log missing from actual directory")
+		assert.Contains(t, fake.failures(), "b.log is present in actual directory but not in expected one")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual, tests.WithIgnore("build", "**/*.log"))
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
+
+func TestWithModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions aren't relevant on windows")
+	}
+	t.Setenv(tests.UpdateEnv, "")
+
+	expected := t.TempDir()
+	writeFiles(t, expected, map[string]string{"file.txt": "file"})
+	actual := t.TempDir()
+	writeFiles(t, actual, map[string]string{"file.txt": "file"})
+	require.NoError(t, os.Chmod(filepath.Join(actual, "file.txt"), 0o600))
+
+	t.Run("error", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual, tests.WithModes())
+
+		// Assert
+		assert.Contains(t, fake.failures(), "file.txt: mode 0600, want 0644")
+	})
+
+	t.Run("success_without", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
+
+func TestWithSymlinkTargets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links need specific privileges on windows")
+	}
+	t.Setenv(tests.UpdateEnv, "")
+
+	// both links have the same target content but not the same target
+	expected := t.TempDir()
+	writeFiles(t, expected, map[string]string{"a.txt": "same", "b.txt": "same"})
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(expected, "link")))
+	actual := t.TempDir()
+	writeFiles(t, actual, map[string]string{"a.txt": "same", "b.txt": "same"})
+	require.NoError(t, os.Symlink("b.txt", filepath.Join(actual, "link")))
+
+	t.Run("error", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual, tests.WithSymlinkTargets())
+
+		// Assert
+		assert.Contains(t, fake.failures(), "link: target b.txt, want a.txt")
+	})
+
+	t.Run("success_without", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
+
+func TestWithEmptyDirs(t *testing.T) {
+	t.Setenv(tests.UpdateEnv, "")
+
+	expected := t.TempDir()
+	writeFiles(t, expected, map[string]string{"file.txt": "file"})
+	require.NoError(t, os.Mkdir(filepath.Join(expected, "empty"), 0o755))
+	actual := t.TempDir()
+	writeFiles(t, actual, map[string]string{"file.txt": "file"})
+
+	t.Run("error", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual, tests.WithEmptyDirs())
+
+		// Assert
+		assert.Contains(t, fake.failures(), "empty missing from actual directory")
+	})
+
+	t.Run("success_without", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
@@ -0,0 +1,432 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences used when rendering with colors.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiReverse = "\x1b[7m"
+	ansiNoRev   = "\x1b[27m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// Renderer represents a function rendering hunks of differences between oldName and newName texts.
+//
+// FormatHunks is the plain unified renderer, Intraline and SideBySide return richer ones.
+type Renderer func(oldName, newName string, hunks []Hunk) []byte
+
+// Granularity represents the unit of changes highlighted within modified lines.
+type Granularity int
+
+const (
+	// GranularityWord highlights changed words (runs of letters, digits and underscores),
+	// runs of spaces and punctuation characters.
+	GranularityWord Granularity = iota
+	// GranularityChar highlights changed characters.
+	GranularityChar
+)
+
+// RenderOption represents a function to customize Intraline and SideBySide renderers.
+type RenderOption func(o *renderOpt)
+
+// WithColor specifies whether ANSI colors must be used.
+//
+// By default, colors are used when standard output supports them (see ColorEnabled).
+func WithColor(enabled bool) RenderOption {
+	return func(o *renderOpt) {
+		o.color = enabled
+	}
+}
+
+// WithGranularity specifies the unit of changes highlighted within modified lines (GranularityWord by default).
+func WithGranularity(granularity Granularity) RenderOption {
+	return func(o *renderOpt) {
+		o.granularity = granularity
+	}
+}
+
+// WithWidth specifies the total width of SideBySide rendering (160 by default).
+func WithWidth(width int) RenderOption {
+	return func(o *renderOpt) {
+		o.width = width
+	}
+}
+
+type renderOpt struct {
+	color       bool
+	granularity Granularity
+	width       int
+}
+
+func newRenderOpt(opts ...RenderOption) *renderOpt {
+	o := &renderOpt{color: ColorEnabled(os.Stdout), width: 160}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// ColorEnabled returns true if ANSI colors can be written to f,
+// i.e. NO_COLOR environment variable isn't set, TERM environment variable isn't "dumb"
+// and f is a terminal (character device).
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// segment represents a part of a line, changed or not compared to its peer line.
+type segment struct {
+	text    string
+	changed bool
+}
+
+// row represents a line of old text (left) paired with a line of new text (right), both being nil for neither side.
+//
+// Equal lines are paired with themselves, deleted and inserted lines of a same change are paired in order.
+type row struct {
+	left, right         *DiffLine
+	leftSegs, rightSegs []segment
+}
+
+// rows returns the rows of hunk lines with their segments highlighted according to granularity.
+func rows(hunk Hunk, granularity Granularity) []row {
+	var result []row
+	lines := hunk.Lines
+	for i := 0; i < len(lines); {
+		// like in FormatHunks, any line neither deleted nor inserted (e.g. an unknown operation) is a context line
+		if op := lines[i].Op; op != LineDelete && op != LineInsert {
+			segs := []segment{{text: lines[i].Content}}
+			result = append(result, row{left: &lines[i], right: &lines[i], leftSegs: segs, rightSegs: segs})
+			i++
+			continue
+		}
+
+		// find the run of deleted lines followed by the run of inserted lines
+		deleted := i
+		for i < len(lines) && lines[i].Op == LineDelete {
+			i++
+		}
+		inserted := i
+		for i < len(lines) && lines[i].Op == LineInsert {
+			i++
+		}
+		dels, inss := lines[deleted:inserted], lines[inserted:i]
+
+		for j := range max(len(dels), len(inss)) {
+			var r row
+			switch {
+			case j < len(dels) && j < len(inss):
+				r.left, r.right = &dels[j], &inss[j]
+				r.leftSegs, r.rightSegs = highlight(dels[j].Content, inss[j].Content, granularity)
+			case j < len(dels):
+				r.left, r.leftSegs = &dels[j], []segment{{text: dels[j].Content}}
+			default:
+				r.right, r.rightSegs = &inss[j], []segment{{text: inss[j].Content}}
+			}
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// highlight returns old and new segments, changed segments being the tokens (see tokenize)
+// not part of a shortest edit script between old and new.
+//
+// When old and new have nothing in common, no segment is marked as changed since the whole lines are.
+func highlight(old, new string, granularity Granularity) ([]segment, []segment) {
+	x, y := tokenize(old, granularity), tokenize(new, granularity)
+	xmatched, ymatched := make([]bool, len(x)), make([]bool, len(y))
+	common := false
+	for _, m := range myers(x, y) {
+		for i, j := m.x, m.y; i < len(x) && j < len(y) && x[i] == y[j]; i, j = i+1, j+1 {
+			xmatched[i], ymatched[j] = true, true
+			common = true
+		}
+	}
+	if !common {
+		return []segment{{text: old}}, []segment{{text: new}}
+	}
+	return segments(x, xmatched), segments(y, ymatched)
+}
+
+// segments merges consecutive tokens with the same matched state into segments.
+func segments(tokens []string, matched []bool) []segment {
+	var result []segment
+	for i, token := range tokens {
+		if n := len(result); n > 0 && result[n-1].changed == !matched[i] {
+			result[n-1].text += token
+			continue
+		}
+		result = append(result, segment{text: token, changed: !matched[i]})
+	}
+	return result
+}
+
+// tokenize splits s into tokens according to granularity.
+func tokenize(s string, granularity Granularity) []string {
+	class := func(r rune) int {
+		switch {
+		case granularity == GranularityChar:
+			return -1 // each character is its own token
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		default:
+			return -1 // each punctuation is its own token
+		}
+	}
+
+	var tokens []string
+	start, previous := 0, 0
+	for i, r := range s {
+		current := class(r)
+		if i > start && (current != previous || current < 0) {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		previous = current
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// style returns the given segments rendered with op colors when enabled,
+// changed segments being reversed with colors or surrounded by [-...-] or {+...+} markers without.
+func (o *renderOpt) style(op LineOp, segs []segment) string {
+	var b strings.Builder
+	color := map[LineOp]string{LineDelete: ansiRed, LineInsert: ansiGreen}[op]
+	if o.color && color != "" {
+		b.WriteString(color)
+	}
+	for _, seg := range segs {
+		switch {
+		case !seg.changed:
+			b.WriteString(seg.text)
+		case o.color:
+			b.WriteString(ansiReverse + seg.text + ansiNoRev)
+		case op == LineDelete:
+			b.WriteString("[-" + seg.text + "-]")
+		default:
+			b.WriteString("{+" + seg.text + "+}")
+		}
+	}
+	if o.color && color != "" {
+		b.WriteString(ansiReset)
+	}
+	return b.String()
+}
+
+// paint returns s with the given ANSI code when colors are enabled.
+func (o *renderOpt) paint(code, s string) string {
+	if !o.color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// Intraline returns a Renderer writing hunks in the “unified diff” format (see FormatHunks),
+// additionally highlighting changes within modified lines.
+//
+// A modified line is a deleted line paired with an inserted line of the same change (in order).
+func Intraline(opts ...RenderOption) Renderer {
+	o := newRenderOpt(opts...)
+	return func(oldName, newName string, hunks []Hunk) []byte {
+		var out bytes.Buffer
+		out.WriteString(o.paint(ansiBold, "--- "+oldName) + "\n")
+		out.WriteString(o.paint(ansiBold, "+++ "+newName) + "\n")
+
+		writeLine := func(op LineOp, line *DiffLine, segs []segment) {
+			out.WriteString(o.style(op, append([]segment{{text: op.prefix()}}, segs...)) + "\n")
+			if line.NoEOL {
+				out.WriteString(noEOL + "\n")
+			}
+		}
+
+		for _, hunk := range hunks {
+			header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+			out.WriteString(o.paint(ansiCyan, header) + "\n")
+
+			// rows of a same change are consecutive, deleted lines are written before inserted ones
+			changes := rows(hunk, o.granularity)
+			for i := 0; i < len(changes); {
+				if r := changes[i]; r.left == r.right {
+					writeLine(LineEqual, r.left, r.leftSegs)
+					i++
+					continue
+				}
+				j := i
+				for j < len(changes) && changes[j].left != changes[j].right {
+					j++
+				}
+				for _, r := range changes[i:j] {
+					if r.left != nil {
+						writeLine(LineDelete, r.left, r.leftSegs)
+					}
+				}
+				for _, r := range changes[i:j] {
+					if r.right != nil {
+						writeLine(LineInsert, r.right, r.rightSegs)
+					}
+				}
+				i = j
+			}
+		}
+		return out.Bytes()
+	}
+}
+
+// SideBySide returns a Renderer writing hunks in two columns, old text on the left and new text on the right,
+// additionally highlighting changes within modified lines (see Intraline).
+//
+// Each line is prefixed by its line number, the gutter between columns shows "|" for a modified line,
+// "<" for a deleted line and ">" for an inserted line. Tabs are expanded to four spaces
+// and lines longer than their column are truncated with "…".
+func SideBySide(opts ...RenderOption) Renderer {
+	o := newRenderOpt(opts...)
+	return func(oldName, newName string, hunks []Hunk) []byte {
+		// compute columns width according to line numbers width
+		numWidth := 1
+		for _, hunk := range hunks {
+			numWidth = max(numWidth, len(strconv.Itoa(hunk.OldStart+hunk.OldLines)), len(strconv.Itoa(hunk.NewStart+hunk.NewLines)))
+		}
+		colWidth := max((o.width-2*(numWidth+1)-3)/2, 10)
+
+		var out bytes.Buffer
+		cell := func(num int, op LineOp, segs []segment, pad bool) string {
+			prefix := strings.Repeat(" ", numWidth)
+			if num > 0 {
+				prefix = fmt.Sprintf("%*d", numWidth, num)
+			}
+			markers := 0
+			if !o.color {
+				markers = 4 // changed segments are surrounded by two characters long markers
+			}
+			segs, length := truncate(segs, colWidth, markers)
+			text := prefix + " " + o.style(op, segs)
+			if pad {
+				text += strings.Repeat(" ", max(colWidth-length, 0))
+			}
+			return text
+		}
+		write := func(left, gutter, right string) {
+			out.WriteString(strings.TrimRight(left+" "+gutter+" "+right, " ") + "\n")
+		}
+
+		header := []segment{{text: oldName}}
+		write(o.paint(ansiBold, cell(0, LineEqual, header, true)), " ", o.paint(ansiBold, cell(0, LineEqual, []segment{{text: newName}}, false)))
+
+		for _, hunk := range hunks {
+			header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+			out.WriteString(o.paint(ansiCyan, header) + "\n")
+
+			oldNum, newNum := hunk.OldStart, hunk.NewStart
+			if hunk.OldLines == 0 {
+				oldNum++
+			}
+			if hunk.NewLines == 0 {
+				newNum++
+			}
+
+			blank := cell(0, LineEqual, nil, true)
+			for _, r := range rows(hunk, o.granularity) {
+				left, right, gutter := blank, "", "|"
+				switch {
+				case r.left == r.right:
+					gutter = " "
+				case r.right == nil:
+					gutter = "<"
+				case r.left == nil:
+					gutter = ">"
+				}
+
+				leftOp, rightOp := LineDelete, LineInsert
+				if r.left == r.right {
+					leftOp, rightOp = LineEqual, LineEqual
+				}
+				if r.left != nil {
+					left = cell(oldNum, leftOp, expandTabs(r.leftSegs), true)
+					oldNum++
+				}
+				if r.right != nil {
+					right = cell(newNum, rightOp, expandTabs(r.rightSegs), false)
+					newNum++
+				}
+				write(left, gutter, right)
+
+				// show missing newlines below their lines
+				leftEOL, rightEOL := blank, ""
+				if r.left != nil && r.left.NoEOL {
+					leftEOL = cell(0, LineEqual, []segment{{text: noEOL}}, true)
+				}
+				if r.right != nil && r.right.NoEOL {
+					rightEOL = cell(0, LineEqual, []segment{{text: noEOL}}, false)
+				}
+				if leftEOL != blank || rightEOL != "" {
+					write(leftEOL, " ", rightEOL)
+				}
+			}
+		}
+		return out.Bytes()
+	}
+}
+
+// expandTabs returns segs with tabs replaced by four spaces.
+func expandTabs(segs []segment) []segment {
+	result := make([]segment, 0, len(segs))
+	for _, seg := range segs {
+		result = append(result, segment{text: strings.ReplaceAll(seg.text, "\t", "    "), changed: seg.changed})
+	}
+	return result
+}
+
+// truncate returns segs truncated (with a trailing "…") to width characters and their resulting length,
+// each changed segment taking markers more characters than its text.
+func truncate(segs []segment, width, markers int) ([]segment, int) {
+	cost := func(seg segment) int {
+		if seg.changed {
+			return markers
+		}
+		return 0
+	}
+
+	length := 0
+	for _, seg := range segs {
+		length += utf8.RuneCountInString(seg.text) + cost(seg)
+	}
+	if length <= width {
+		return segs, length
+	}
+
+	result := make([]segment, 0, len(segs))
+	remaining := width - 1
+	for _, seg := range segs {
+		available := remaining - cost(seg)
+		if available <= 0 {
+			break
+		}
+		runes := []rune(seg.text)
+		n := min(len(runes), available)
+		result = append(result, segment{text: string(runes[:n]), changed: seg.changed})
+		remaining -= n + cost(seg)
+	}
+	return append(result, segment{text: "…"}), width - remaining
+}
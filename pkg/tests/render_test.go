@@ -0,0 +1,183 @@
+package tests_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+var (
+	renderOld = []byte("package main\n\nfunc main() {\n\tprintln(\"hello world\")\n}\n")
+	renderNew = []byte("package main\n\nfunc main() {\n\tprintln(\"hello gopher\")\n\tos.Exit(1)\n}")
+
+	// unknownOp is a hunk with a line operation neither produced by Hunks nor known by renderers.
+	unknownOp = []tests.Hunk{{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1, Lines: []tests.DiffLine{{Op: "", Content: "x"}}}}
+)
+
+func TestIntraline(t *testing.T) {
+	hunks := tests.Hunks(renderOld, renderNew, tests.WithContext(1))
+
+	t.Run("success_words", func(t *testing.T) {
+		// Act
+		out := tests.Intraline(tests.WithColor(false))("a", "b", hunks)
+
+		// Assert
+		expected := "--- a\n+++ b\n@@ -3,3 +3,4 @@\n func main() {\n" +
+			"-\tprintln(\"hello [-world-]\")\n" +
+			"-}\n" +
+			"+\tprintln(\"hello {+gopher+}\")\n" +
+			"+\tos.Exit(1)\n" +
+			"+}\n" +
+			"\\ No newline at end of file\n"
+		assert.Equal(t, expected, string(out))
+	})
+
+	t.Run("success_chars", func(t *testing.T) {
+		// Act
+		out := tests.Intraline(tests.WithColor(false), tests.WithGranularity(tests.GranularityChar))("a", "b", hunks)
+
+		// Assert
+		assert.Contains(t, string(out), "-\tprintln(\"hello [-w-]or[-ld-]\")\n")
+		assert.Contains(t, string(out), "+\tprintln(\"hello {+g+}o{+phe+}r\")\n")
+	})
+
+	t.Run("success_color", func(t *testing.T) {
+		// Act
+		out := tests.Intraline(tests.WithColor(true))("a", "b", hunks)
+
+		// Assert
+		expected := "\x1b[1m--- a\x1b[0m\n\x1b[1m+++ b\x1b[0m\n\x1b[36m@@ -3,3 +3,4 @@\x1b[0m\n func main() {\n" +
+			"\x1b[31m-\tprintln(\"hello \x1b[7mworld\x1b[27m\")\x1b[0m\n" +
+			"\x1b[31m-}\x1b[0m\n" +
+			"\x1b[32m+\tprintln(\"hello \x1b[7mgopher\x1b[27m\")\x1b[0m\n" +
+			"\x1b[32m+\tos.Exit(1)\x1b[0m\n" +
+			"\x1b[32m+}\x1b[0m\n" +
+			"\\ No newline at end of file\n"
+		assert.Equal(t, expected, string(out))
+	})
+
+	t.Run("success_unknown_op", func(t *testing.T) {
+		// Act
+		out := tests.Intraline(tests.WithColor(false))("a", "b", unknownOp)
+
+		// Assert
+		assert.Equal(t, "--- a\n+++ b\n@@ -1,1 +1,1 @@\n x\n", string(out))
+	})
+}
+
+func TestSideBySide(t *testing.T) {
+	hunks := tests.Hunks(renderOld, renderNew, tests.WithContext(1))
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		out := tests.SideBySide(tests.WithColor(false), tests.WithWidth(80))("a", "b", hunks)
+
+		// Assert
+		// tabs are expanded to four spaces
+		expected := `  a                                        b
+@@ -3,3 +3,4 @@
+3 func main() {                          3 func main() {
+4     println("hello [-world-]")       | 4     println("hello {+gopher+}")
+5 }                                    | 5     os.Exit(1)
+                                       > 6 }
+                                           \ No newline at end of file
+`
+		assert.Equal(t, expected, string(out))
+	})
+
+	t.Run("success_truncate", func(t *testing.T) {
+		// Act
+		out := tests.SideBySide(tests.WithColor(false), tests.WithWidth(60))("a", "b", hunks)
+
+		// Assert
+		// markers are part of columns width
+		expected := `  a                              b
+@@ -3,3 +3,4 @@
+3 func main() {                3 func main() {
+4     println("hello [-wo-]… | 4     println("hello {+go+}…
+5 }                          | 5     os.Exit(1)
+                             > 6 }
+                                 \ No newline at end of fi…
+`
+		assert.Equal(t, expected, string(out))
+	})
+
+	t.Run("success_min_width", func(t *testing.T) {
+		// Act
+		out := tests.SideBySide(tests.WithColor(false), tests.WithWidth(0))("a", "b", hunks)
+
+		// Assert
+		assert.Contains(t, string(out), "4     print… | 4     print…\n") // columns are at least 10 characters wide
+	})
+
+	t.Run("success_color", func(t *testing.T) {
+		// Act
+		out := tests.SideBySide(tests.WithColor(true), tests.WithWidth(80))("a", "b", hunks)
+
+		// Assert
+		assert.Contains(t, string(out), "\x1b[36m@@ -3,3 +3,4 @@\x1b[0m\n")
+		// without markers, columns are aligned on text only
+		assert.Contains(t, string(out), "4 \x1b[31m    println(\"hello \x1b[7mworld\x1b[27m\")\x1b[0m"+
+			"           | 4 \x1b[32m    println(\"hello \x1b[7mgopher\x1b[27m\")\x1b[0m\n")
+		assert.Contains(t, string(out), "> 6 \x1b[32m}\x1b[0m\n")
+	})
+
+	t.Run("success_unknown_op", func(t *testing.T) {
+		// Act
+		out := tests.SideBySide(tests.WithColor(false), tests.WithWidth(20))("a", "b", unknownOp)
+
+		// Assert
+		assert.Equal(t, "  a              b\n@@ -1,1 +1,1 @@\n1 x            1 x\n", string(out))
+	})
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Run("false_nil", func(t *testing.T) {
+		// Act
+		enabled := tests.ColorEnabled(nil)
+
+		// Assert
+		assert.False(t, enabled)
+	})
+
+	t.Run("false_no_color", func(t *testing.T) {
+		// Arrange
+		t.Setenv("NO_COLOR", "1")
+
+		// Act
+		enabled := tests.ColorEnabled(os.Stdout)
+
+		// Assert
+		assert.False(t, enabled)
+	})
+
+	t.Run("false_regular_file", func(t *testing.T) {
+		// Arrange
+		t.Setenv("NO_COLOR", "")
+		file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = file.Close() })
+
+		// Act
+		enabled := tests.ColorEnabled(file)
+
+		// Assert
+		assert.False(t, enabled)
+	})
+}
+
+func TestWithRenderer(t *testing.T) {
+	// Act
+	errs := assertFiles(t, string(renderOld), string(renderNew), tests.WithRenderer(tests.Intraline(tests.WithColor(false))))
+
+	// Assert
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "diff ")
+	assert.Contains(t, errs[0], "[-world-]")
+	assert.Contains(t, errs[0], "{+gopher+}")
+}
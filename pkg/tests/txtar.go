@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Archive represents a txtar archive, a trivial text-based file archive format.
+//
+// An archive is a comment followed by a sequence of files, each one introduced by a marker line "-- name --":
+//
+//	some comment
+//	-- hello.txt --
+//	Hello world!
+//	-- dir/nested.txt --
+//	nested content
+//	-- empty/ --
+//
+// A file name ending with a slash represents an empty directory (its content is ignored).
+type Archive struct {
+	Comment []byte
+	Files   []ArchiveFile
+}
+
+// ArchiveFile represents a single file in an Archive.
+type ArchiveFile struct {
+	Name string
+	Data []byte
+}
+
+// ParseArchive parses a txtar archive.
+//
+// Lines looking like markers but with an empty name are kept as content.
+func ParseArchive(data []byte) *Archive {
+	archive := &Archive{}
+	content := &archive.Comment
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 {
+			end = len(data)
+		}
+		line := data[:end]
+		data = data[end:]
+
+		if name, ok := marker(line); ok {
+			archive.Files = append(archive.Files, ArchiveFile{Name: name})
+			content = &archive.Files[len(archive.Files)-1].Data
+			continue
+		}
+		*content = append(*content, line...)
+	}
+	return archive
+}
+
+// marker returns the file name of line if it's a marker line ("-- name --").
+func marker(line []byte) (string, bool) {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(line) < len("-- x --") || !bytes.HasPrefix(line, []byte("-- ")) || !bytes.HasSuffix(line, []byte(" --")) {
+		return "", false
+	}
+	name := strings.TrimSpace(string(line[3 : len(line)-3]))
+	return name, name != ""
+}
+
+// validate returns an error if any file name of archive isn't a valid relative slash separated path (see fs.ValidPath)
+// or if it's present more than once in archive.
+func (a *Archive) validate() error {
+	errs := make([]error, 0, len(a.Files))
+	names := make(map[string]struct{}, len(a.Files))
+	for _, file := range a.Files {
+		name := strings.TrimSuffix(file.Name, "/")
+		if !fs.ValidPath(name) {
+			errs = append(errs, fmt.Errorf("invalid archive file name %s", file.Name))
+			continue
+		}
+		if _, ok := names[name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate archive file name %s", file.Name))
+			continue
+		}
+		names[name] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteDir writes all files (and directories) of the archive into dir.
+//
+// Parent directories are created when needed, files are written with 0o644 permissions
+// and directories with 0o755 permissions (applied explicitly, meaning the process umask doesn't alter them).
+func (a *Archive) WriteDir(dir string) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	for _, file := range a.Files {
+		// handle directories
+		if name := strings.TrimSuffix(file.Name, "/"); name != file.Name {
+			if err := mkdirAll(dir, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// handle files
+		if err := mkdirAll(dir, path.Dir(file.Name)); err != nil {
+			return err
+		}
+		dest := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.WriteFile(dest, file.Data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+		if err := os.Chmod(dest, 0o644); err != nil {
+			return fmt.Errorf("failed to update %s permissions: %w", dest, err)
+		}
+	}
+	return nil
+}
+
+// mkdirAll creates the directory name (a slash separated path relative to dir) and its missing parents inside dir
+// with 0o755 permissions (applied explicitly since the process umask may have altered them).
+func mkdirAll(dir, name string) error {
+	if name == "." {
+		return nil
+	}
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		dest := filepath.Join(dir, filepath.FromSlash(path.Join(parts[:i+1]...)))
+		if err := os.Mkdir(dest, 0o755); err != nil {
+			if errors.Is(err, fs.ErrExist) {
+				continue
+			}
+			return fmt.Errorf("failed to create folder %s: %w", dest, err)
+		}
+		if err := os.Chmod(dest, 0o755); err != nil {
+			return fmt.Errorf("failed to update folder %s permissions: %w", dest, err)
+		}
+	}
+	return nil
+}
+
+// FS returns an in-memory filesystem with all files (and directories) of the archive.
+func (a *Archive) FS() fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, file := range a.Files {
+		if name := strings.TrimSuffix(file.Name, "/"); name != file.Name {
+			fsys[name] = &fstest.MapFile{Mode: fs.ModeDir | 0o755}
+			continue
+		}
+		fsys[file.Name] = &fstest.MapFile{Data: file.Data, Mode: 0o644}
+	}
+	return fsys
+}
+
+// entries returns archive files (and directories, including implicit parent ones)
+// as readDirInMap would return them once written with WriteDir.
+func (a *Archive) entries() map[string]entry {
+	entries := map[string]entry{}
+	for _, file := range a.Files {
+		name := strings.TrimSuffix(file.Name, "/")
+		if name == file.Name {
+			entries[name] = entry{perm: 0o644, content: file.Data}
+		} else {
+			entries[name] = entry{typ: fs.ModeDir, perm: 0o755}
+		}
+
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			entries[dir] = entry{typ: fs.ModeDir, perm: 0o755}
+		}
+	}
+	return entries
+}
+
+// TxtarDir writes the given txtar archive (see Archive) into a new temporary directory and returns it.
+//
+// It will fail (and stop) t if the archive is invalid or cannot be written.
+// The temporary directory is removed at the end of t (see testing.TB TempDir).
+func TxtarDir(t testing.TB, archive string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	err := ParseArchive([]byte(archive)).WriteDir(dir)
+	require.NoError(t, err, "failed to write txtar archive")
+	return dir
+}
+
+// TxtarFS returns an in-memory filesystem with all files (and directories) of the given txtar archive (see Archive).
+func TxtarFS(archive string) fstest.MapFS {
+	return ParseArchive([]byte(archive)).FS()
+}
+
+// AssertEqualTxtar compares the given expected txtar archive (see Archive) and actual directory (and its subdirectories).
+//
+// It behaves like AssertEqualDir with the same options, archive files being expected with 0o644 permissions
+// and archive directories with 0o755 permissions.
+// Since the archive lives in test sources, it isn't updated in update mode (see Updating).
+func AssertEqualTxtar(t testing.TB, archive, actual string, opts ...AssertOption) {
+	o := newAssertOpt(opts...)
+
+	expected := ParseArchive([]byte(archive))
+	if err := expected.validate(); err != nil {
+		assert.NoError(t, err, "invalid expected txtar archive")
+		return
+	}
+	expectedEntries := o.filter(expected.entries())
+
+	// read all files in actual directory
+	actualEntries, err := readDirInMap(actual)
+	assert.NoError(t, err, "failed to completely read actual %s folder and its children", actual)
+	actualEntries = o.filter(actualEntries)
+
+	assertEqualEntries(t, expectedEntries, actualEntries, o)
+}
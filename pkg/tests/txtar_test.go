@@ -0,0 +1,156 @@
+package tests_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+const archive = `some comment
+-- hello.txt --
+Hello world!
+-- dir/nested.txt --
+nested content
+-- empty/ --
+`
+
+func TestParseArchive(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// Act
+		a := tests.ParseArchive([]byte(archive))
+
+		// Assert
+		assert.Equal(t, &tests.Archive{
+			Comment: []byte("some comment\n"),
+			Files: []tests.ArchiveFile{
+				{Name: "hello.txt", Data: []byte("Hello world!\n")},
+				{Name: "dir/nested.txt", Data: []byte("nested content\n")},
+				{Name: "empty/"},
+			},
+		}, a)
+	})
+
+	t.Run("success_no_trailing_newline", func(t *testing.T) {
+		// Act
+		a := tests.ParseArchive([]byte("-- file.txt --\r\ncontent"))
+
+		// Assert
+		assert.Equal(t, &tests.Archive{Files: []tests.ArchiveFile{{Name: "file.txt", Data: []byte("content")}}}, a)
+	})
+
+	t.Run("success_empty_marker_name", func(t *testing.T) {
+		// Act
+		a := tests.ParseArchive([]byte("-- file.txt --\n--  --\n-- --\n"))
+
+		// Assert
+		assert.Equal(t, &tests.Archive{Files: []tests.ArchiveFile{{Name: "file.txt", Data: []byte("--  --\n-- --\n")}}}, a)
+	})
+}
+
+func TestArchive_WriteDir(t *testing.T) {
+	t.Run("error_invalid_name", func(t *testing.T) {
+		// Arrange
+		a := tests.ParseArchive([]byte("-- ../escape.txt --\n"))
+
+		// Act
+		err := a.WriteDir(t.TempDir())
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid archive file name ../escape.txt")
+	})
+
+	t.Run("error_duplicate_name", func(t *testing.T) {
+		// Arrange
+		a := tests.ParseArchive([]byte("-- file.txt --\na\n-- dir/ --\n-- file.txt --\nb\n-- dir --\n"))
+
+		// Act
+		err := a.WriteDir(t.TempDir())
+
+		// Assert
+		assert.ErrorContains(t, err, "duplicate archive file name file.txt")
+		assert.ErrorContains(t, err, "duplicate archive file name dir")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+
+		// Act
+		err := tests.ParseArchive([]byte(archive)).WriteDir(dir)
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualFS(t, fstest.MapFS{
+			"hello.txt":      {Data: []byte("Hello world!\n")},
+			"dir/nested.txt": {Data: []byte("nested content\n")},
+			"empty":          {Mode: fs.ModeDir},
+		}, os.DirFS(dir), tests.WithEmptyDirs())
+		if runtime.GOOS == "windows" {
+			return // permissions aren't relevant on windows
+		}
+		for name, perm := range map[string]fs.FileMode{"hello.txt": 0o644, "dir": 0o755, "dir/nested.txt": 0o644, "empty": 0o755} {
+			info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+			require.NoError(t, err)
+			assert.Equal(t, perm, info.Mode().Perm(), name)
+		}
+	})
+}
+
+func TestTxtarFS(t *testing.T) {
+	// Act
+	fsys := tests.TxtarFS(archive)
+
+	// Assert
+	assert.NoError(t, fstest.TestFS(fsys, "hello.txt", "dir/nested.txt", "empty"))
+	content, err := fs.ReadFile(fsys, "dir/nested.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "nested content\n", string(content))
+}
+
+func TestAssertEqualTxtar(t *testing.T) {
+	t.Setenv(tests.UpdateEnv, "")
+
+	t.Run("error_different", func(t *testing.T) {
+		// Arrange
+		dir := tests.TxtarDir(t, archive)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("Hello you!\n"), 0o644))
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualTxtar(fake, archive, dir)
+
+		// Assert
+		assert.Contains(t, fake.failures(), "hello.txt is different from expected")
+	})
+
+	t.Run("error_duplicate_name", func(t *testing.T) {
+		// Arrange
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualTxtar(fake, "-- file.txt --\na\n-- file.txt --\nb\n", t.TempDir())
+
+		// Assert
+		assert.Contains(t, fake.failures(), "duplicate archive file name file.txt")
+	})
+
+	t.Run("success_round_trip", func(t *testing.T) {
+		// Arrange
+		dir := tests.TxtarDir(t, archive)
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualTxtar(fake, archive, dir, tests.WithModes(), tests.WithEmptyDirs())
+
+		// Assert
+		assert.Empty(t, fake.errors)
+	})
+}
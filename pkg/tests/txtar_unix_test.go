@@ -0,0 +1,27 @@
+//go:build unix
+
+package tests_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestAssertEqualTxtar_Umask(t *testing.T) {
+	// Arrange
+	t.Setenv(tests.UpdateEnv, "")
+	previous := syscall.Umask(0o077)
+	t.Cleanup(func() { syscall.Umask(previous) })
+	dir := tests.TxtarDir(t, archive)
+	fake := &fakeT{TB: t}
+
+	// Act
+	tests.AssertEqualTxtar(fake, archive, dir, tests.WithModes(), tests.WithEmptyDirs())
+
+	// Assert
+	assert.Empty(t, fake.errors)
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// UpdateEnv is the environment variable enabling golden files update mode when set to a true value (e.g. "1" or "true").
+//
+// It's the way to enable update mode for all packages at once (UPDATE_GOLDEN=1 go test ./...).
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// update is the test flag enabling golden files update mode.
+//
+// It's only defined in test binaries of packages importing tests, meaning it must be given for a single package
+// (go test ./pkg -update-golden), other packages would reject it.
+var update = flag.Bool("update-golden", false, "overwrite expected files and directories with actual ones in AssertEqualFile and AssertEqualDir")
+
+// Updating returns true when golden files update mode is enabled,
+// either with UPDATE_GOLDEN environment variable (e.g. UPDATE_GOLDEN=1 go test ./...)
+// or with -update-golden test flag (only for a single package, e.g. go test ./pkg -update-golden).
+//
+// In update mode, AssertEqualFile and AssertEqualDir don't fail on differences
+// but overwrite expected side with actual content instead (stale expected files are removed).
+func Updating() bool {
+	if *update {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return enabled
+}
+
+// updateFile overwrites expected file with actual file content and permissions.
+func updateFile(expected, actual string) error {
+	info, err := os.Lstat(actual)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", actual, err)
+	}
+
+	// remove expected in case it's not a file anymore (directory, symbolic link)
+	if err := os.RemoveAll(expected); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", expected, err)
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(actual)
+		if err != nil {
+			return fmt.Errorf("failed to read link %s: %w", actual, err)
+		}
+		if err := os.Symlink(target, expected); err != nil {
+			return fmt.Errorf("failed to create link %s: %w", expected, err)
+		}
+		return nil
+	}
+
+	content, err := os.ReadFile(actual)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", actual, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(expected), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(expected), err)
+	}
+	if err := os.WriteFile(expected, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", expected, err)
+	}
+	// permissions are applied explicitly since the process umask may have altered them
+	if err := os.Chmod(expected, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to update %s permissions: %w", expected, err)
+	}
+	return nil
+}
+
+// updateDir updates expected directory to match actual directory,
+// expectedEntries and actualEntries being both directories contents as read by readDirInMap.
+func updateDir(t testing.TB, expected, actual string, expectedEntries, actualEntries map[string]entry, o *assertOpt) error {
+	t.Helper()
+
+	var errs []error
+
+	// remove stale expected entries, in reverse order to remove directories content before directories
+	stale := sortedKeys(expectedEntries)
+	slices.Reverse(stale)
+	for _, name := range stale {
+		if _, ok := actualEntries[name]; ok {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(expected, filepath.FromSlash(name))); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", name, err))
+			continue
+		}
+		t.Logf("golden update: removed %s", name)
+	}
+
+	// create or overwrite different entries
+	var dirs []string
+	for _, name := range sortedKeys(actualEntries) {
+		actualEntry := actualEntries[name]
+		expectedEntry, ok := expectedEntries[name]
+		if ok && expectedEntry.equal(actualEntry, o) {
+			continue
+		}
+
+		dest := filepath.Join(expected, filepath.FromSlash(name))
+		if actualEntry.typ == fs.ModeDir {
+			// an existing directory is kept with its content (only its permissions differ),
+			// anything else is replaced by a directory
+			if ok && expectedEntry.typ != fs.ModeDir {
+				if err := os.RemoveAll(dest); err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove %s: %w", name, err))
+					continue
+				}
+			}
+			if err := os.MkdirAll(dest, 0o755); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create directory %s: %w", name, err))
+				continue
+			}
+			dirs = append(dirs, name)
+		} else if err := updateFile(dest, filepath.Join(actual, filepath.FromSlash(name))); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if ok {
+			t.Logf("golden update: updated %s", name)
+		} else {
+			t.Logf("golden update: created %s", name)
+		}
+	}
+
+	// directories permissions are applied once their content is updated, deepest ones first
+	slices.Reverse(dirs)
+	for _, name := range dirs {
+		if err := os.Chmod(filepath.Join(expected, filepath.FromSlash(name)), actualEntries[name].perm); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update directory %s permissions: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,203 @@
+package tests_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestUpdating(t *testing.T) {
+	t.Run("false_unset", func(t *testing.T) {
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "")
+
+		// Act
+		updating := tests.Updating()
+
+		// Assert
+		assert.False(t, updating)
+	})
+
+	t.Run("false_env_false", func(t *testing.T) {
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "false")
+
+		// Act
+		updating := tests.Updating()
+
+		// Assert
+		assert.False(t, updating)
+	})
+
+	t.Run("true_env", func(t *testing.T) {
+		for _, value := range []string{"1", "true"} {
+			// Arrange
+			t.Setenv(tests.UpdateEnv, value)
+
+			// Act
+			updating := tests.Updating()
+
+			// Assert
+			assert.True(t, updating, value)
+		}
+	})
+}
+
+func TestAssertEqualFile_Update(t *testing.T) {
+	t.Run("success_overwrite", func(t *testing.T) {
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "1")
+		tmp := t.TempDir()
+		expected := filepath.Join(tmp, "expected.txt")
+		require.NoError(t, os.WriteFile(expected, []byte("old content"), filesystem.RwRR))
+		actual := filepath.Join(tmp, "actual.txt")
+		require.NoError(t, os.WriteFile(actual, []byte("new content"), filesystem.Rw))
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFile(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+		assert.Equal(t, []string{"golden update: updated " + expected}, fake.logs)
+		content, err := os.ReadFile(expected)
+		require.NoError(t, err)
+		assert.Equal(t, "new content", string(content))
+		if runtime.GOOS == "windows" {
+			return // permissions aren't relevant on windows
+		}
+		info, err := os.Stat(expected)
+		require.NoError(t, err)
+		assert.Equal(t, filesystem.Rw, info.Mode().Perm())
+	})
+
+	t.Run("success_create", func(t *testing.T) {
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "1")
+		tmp := t.TempDir()
+		expected := filepath.Join(tmp, "testdata", "expected.txt")
+		actual := filepath.Join(tmp, "actual.txt")
+		require.NoError(t, os.WriteFile(actual, []byte("new content"), filesystem.RwRR))
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFile(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+		assert.Equal(t, []string{"golden update: created " + expected}, fake.logs)
+		tests.AssertEqualFile(t, actual, expected)
+	})
+
+	t.Run("success_unchanged", func(t *testing.T) {
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "1")
+		tmp := t.TempDir()
+		expected := filepath.Join(tmp, "expected.txt")
+		require.NoError(t, os.WriteFile(expected, []byte("content\r\n"), filesystem.RwRR))
+		actual := filepath.Join(tmp, "actual.txt")
+		require.NoError(t, os.WriteFile(actual, []byte("content\n"), filesystem.RwRR))
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualFile(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+		assert.Empty(t, fake.logs)
+		content, err := os.ReadFile(expected)
+		require.NoError(t, err)
+		assert.Equal(t, "content\r\n", string(content))
+	})
+}
+
+func TestAssertEqualDir_Update(t *testing.T) {
+	t.Run("success_stale_files", func(t *testing.T) {
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "1")
+		expected := t.TempDir()
+		writeFiles(t, expected, map[string]string{"kept.txt": "kept", "stale.txt": "stale", "stale/file.txt": "stale"})
+		actual := t.TempDir()
+		writeFiles(t, actual, map[string]string{"kept.txt": "kept", "new/file.txt": "new"})
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual)
+
+		// Assert
+		assert.Empty(t, fake.errors)
+		assert.Equal(t, []string{
+			"golden update: removed stale/file.txt",
+			"golden update: removed stale.txt",
+			"golden update: removed stale",
+			"golden update: created new",
+			"golden update: created new/file.txt",
+		}, fake.logs)
+		assert.NoFileExists(t, filepath.Join(expected, "stale.txt"))
+		assert.NoDirExists(t, filepath.Join(expected, "stale"))
+		tests.AssertEqualDir(t, actual, expected, tests.WithEmptyDirs())
+	})
+
+	t.Run("success_dir_modes", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("permissions aren't relevant on windows")
+		}
+
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "1")
+		expected := t.TempDir()
+		writeFiles(t, expected, map[string]string{"sub/file.txt": "file"})
+		actual := t.TempDir()
+		writeFiles(t, actual, map[string]string{"sub/file.txt": "file"})
+		require.NoError(t, os.Chmod(filepath.Join(actual, "sub"), fs.FileMode(0o700)))
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual, tests.WithModes())
+
+		// Assert
+		assert.Empty(t, fake.errors)
+		assert.Equal(t, []string{"golden update: updated sub"}, fake.logs)
+		assert.FileExists(t, filepath.Join(expected, "sub", "file.txt"))
+		info, err := os.Stat(filepath.Join(expected, "sub"))
+		require.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0o700), info.Mode().Perm())
+	})
+
+	t.Run("success_symlinks", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("symbolic links need specific privileges on windows")
+		}
+
+		// Arrange
+		t.Setenv(tests.UpdateEnv, "1")
+		expected := t.TempDir()
+		writeFiles(t, expected, map[string]string{"a.txt": "a", "b.txt": "b"})
+		require.NoError(t, os.Symlink("a.txt", filepath.Join(expected, "link")))
+		actual := t.TempDir()
+		writeFiles(t, actual, map[string]string{"a.txt": "a", "b.txt": "b"})
+		require.NoError(t, os.Symlink("b.txt", filepath.Join(actual, "link")))
+		fake := &fakeT{TB: t}
+
+		// Act
+		tests.AssertEqualDir(fake, expected, actual, tests.WithSymlinkTargets())
+
+		// Assert
+		assert.Empty(t, fake.errors)
+		assert.Equal(t, []string{"golden update: updated link"}, fake.logs)
+		info, err := os.Lstat(filepath.Join(expected, "link"))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&fs.ModeSymlink)
+		target, err := os.Readlink(filepath.Join(expected, "link"))
+		require.NoError(t, err)
+		assert.Equal(t, "b.txt", target)
+	})
+}
@@ -0,0 +1,234 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// ErrTransactionDone is returned by Transaction Commit and Rollback when the transaction was already committed or rolled back.
+var ErrTransactionDone = errors.New("transaction has already been committed or rolled back")
+
+// WithTransaction specifies that CopyDir must be transactional.
+//
+// When the copy fails, destination directory is restored to its exact prior state,
+// created files and directories are removed and overwritten files are restored.
+// See CopyDirTx to decide when to commit or rollback the copy.
+func WithTransaction() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.transaction = true
+	}
+}
+
+// WithBackupDir specifies the directory where a transactional CopyDir (see WithTransaction and CopyDirTx)
+// creates its backup directory for overwritten files.
+//
+// It defaults to the destination directory parent, or to the OS temporary directory when the parent isn't writable.
+func WithBackupDir(dir string) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.backupDir = dir
+	}
+}
+
+// Transaction represents a transactional CopyDir which can be either committed or rolled back.
+//
+// While the transaction is pending, overwritten files are kept in a backup directory (see WithBackupDir).
+// They're moved into it, or copied then removed when it isn't on the same device (in which case
+// only their content, permissions and owner are kept).
+type Transaction struct {
+	// backupdir is the directory where overwritten files are moved (lazily created).
+	backupdir string
+	// parent is the directory where backupdir is created.
+	parent string
+	// fallback is true when backupdir may be created in OS temporary directory if parent isn't writable.
+	fallback bool
+	// owners is true when the copy may change existing directories owner.
+	owners bool
+
+	// done is true once the transaction was committed or rolled back.
+	done bool
+
+	// journal is the ordered list of changes made in destination.
+	journal []change
+	// recorded is the set of paths already in journal.
+	recorded map[string]struct{}
+}
+
+// change represents a change made on destination by a transactional copy.
+type change struct {
+	// path is the changed destination path.
+	path string
+	// backup is the path where the previous file was moved, empty when path was created.
+	backup string
+	// owner is true when path is an existing directory whose owner (uid and gid) may have been changed.
+	owner    bool
+	uid, gid int
+}
+
+// CopyDirTx copies recursively a provided directory as destdir like CopyDir,
+// but keeps track of all changes made in destdir to be able to restore it.
+//
+// In case of failure, destdir is restored to its prior state and the returned Transaction is nil.
+// Otherwise, the returned Transaction must be either committed (to remove backups) or rolled back.
+func CopyDirTx(srcdir, destdir string, opts ...FSOption) (*Transaction, error) {
+	o := newFSOpt(opts...)
+	if o.patternErr != nil {
+		return nil, o.patternErr
+	}
+	tx := &Transaction{parent: o.backupDir, owners: o.owner != nil, recorded: map[string]struct{}{}}
+	if tx.parent == "" {
+		tx.parent = filepath.Dir(destdir)
+		tx.fallback = true
+	}
+	c := &copier{fsOpt: o, links: map[inode]string{}, tx: tx}
+
+	if err := c.copyDir(srcdir, destdir, ""); err != nil {
+		return nil, errors.Join(err, tx.Rollback())
+	}
+	return tx, nil
+}
+
+// Commit validates all changes made in destination directory by removing backups of overwritten files.
+func (tx *Transaction) Commit() error {
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+
+	if tx.backupdir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(tx.backupdir); err != nil {
+		return fmt.Errorf("failed to remove backup directory %s: %w", tx.backupdir, err)
+	}
+	return nil
+}
+
+// Rollback restores destination directory to its state prior to the copy.
+//
+// Created files and directories are removed and overwritten files are restored from their backup.
+func (tx *Transaction) Rollback() error {
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+
+	errs := make([]error, 0, len(tx.journal))
+	for i := len(tx.journal) - 1; i >= 0; i-- {
+		change := tx.journal[i]
+		switch {
+		case change.owner:
+			if err := os.Lchown(change.path, change.uid, change.gid); err != nil && !errors.Is(err, fs.ErrPermission) {
+				errs = append(errs, fmt.Errorf("failed to restore %s owner: %w", change.path, err))
+			}
+		case change.backup != "":
+			// the copy may have created something else at path (e.g. a directory in place of a file)
+			if err := os.RemoveAll(change.path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", change.path, err))
+				continue
+			}
+			if err := move(change.backup, change.path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore %s: %w", change.path, err))
+			}
+		default:
+			if err := os.RemoveAll(change.path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", change.path, err))
+			}
+		}
+	}
+
+	if tx.backupdir != "" {
+		if err := os.RemoveAll(tx.backupdir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove backup directory %s: %w", tx.backupdir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// record saves the current state of dest before it's created or overwritten by the copy.
+//
+// An existing file is moved into the backup directory to be restored on rollback.
+func (tx *Transaction) record(dest string) error {
+	if _, ok := tx.recorded[dest]; ok {
+		return nil // already recorded (e.g. retried operation)
+	}
+
+	info, err := os.Lstat(dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		tx.add(change{path: dest})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// existing directories are kept as is, only their owner may change
+	if info.IsDir() {
+		if tx.owners {
+			uid, gid := owner(info)
+			tx.add(change{path: dest, owner: uid != -1 || gid != -1, uid: uid, gid: gid})
+		}
+		return nil
+	}
+
+	if tx.backupdir == "" {
+		backupdir, err := os.MkdirTemp(tx.parent, ".backup-")
+		if errors.Is(err, fs.ErrPermission) && tx.fallback {
+			backupdir, err = os.MkdirTemp("", ".backup-")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		tx.backupdir = backupdir
+	}
+
+	// renaming keeps the exact file (content, permissions, owner, attributes, links)
+	backup := filepath.Join(tx.backupdir, strconv.Itoa(len(tx.journal)))
+	if err := move(dest, backup); err != nil {
+		return err
+	}
+	tx.add(change{path: dest, backup: backup})
+	return nil
+}
+
+// add appends a change to the journal.
+func (tx *Transaction) add(change change) {
+	tx.journal = append(tx.journal, change)
+	tx.recorded[change.path] = struct{}{}
+}
+
+// move renames src as dest, falling back to a copy of src followed by its removal
+// when both aren't on the same device (only regular files and symbolic links can be copied).
+func move(src, dest string) error {
+	err := os.Rename(src, dest)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, lerr := os.Lstat(src)
+	if lerr != nil {
+		return lerr
+	}
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, dest); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		perm := info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+		if err := CopyFile(src, dest, WithPerm(perm), WithPreserveOwner()); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,204 @@
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+func TestCopyDirTx(t *testing.T) {
+	srcdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "a.txt"), []byte("new a"), filesystem.RwRR))
+	require.NoError(t, os.Mkdir(filepath.Join(srcdir, "b"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "b", "c.txt"), []byte("new c"), filesystem.RwRR))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "d.txt"), []byte("new d"), filesystem.RwRR))
+
+	// destdir returns a destination directory with an existing a.txt file, its snapshot and its parent directory
+	destdir := func(t *testing.T) (string, string, string) {
+		t.Helper()
+		parent := t.TempDir()
+		destdir := filepath.Join(parent, "dest")
+		require.NoError(t, os.Mkdir(destdir, filesystem.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(filepath.Join(destdir, "a.txt"), []byte("old a"), filesystem.Rw))
+		require.NoError(t, os.WriteFile(filepath.Join(destdir, "e.txt"), []byte("old e"), filesystem.RwRR))
+
+		snapshot := filepath.Join(t.TempDir(), "snapshot")
+		require.NoError(t, filesystem.CopyDir(destdir, snapshot))
+		return destdir, snapshot, parent
+	}
+
+	t.Run("error_rollback", func(t *testing.T) {
+		// Arrange
+		destdir, snapshot, parent := destdir(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(destdir, "d.txt", "sub"), filesystem.RwxRxRxRx)) // make d.txt creation fail
+		require.NoError(t, filesystem.CopyDir(destdir, snapshot))
+
+		// Act
+		tx, err := filesystem.CopyDirTx(srcdir, destdir)
+
+		// Assert
+		assert.Nil(t, tx)
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, filesystem.OpCreate, errs[0].Op)
+		tests.AssertEqualDir(t, snapshot, destdir)
+		assert.NoDirExists(t, filepath.Join(destdir, "b"))
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(filepath.Join(destdir, "a.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, filesystem.Rw, info.Mode().Perm())
+		}
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1) // no backup directory left
+	})
+
+	t.Run("error_rollback_created_destdir", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"a.txt":     &fstest.MapFile{Data: []byte("a")},
+			"b/c.txt":   &fstest.MapFile{Data: []byte("c")},
+			"z\x00.txt": &fstest.MapFile{Data: []byte("z")}, // invalid destination name
+		}
+		destdir := filepath.Join(t.TempDir(), "dest")
+
+		// Act
+		err := filesystem.CopyDir(".", destdir, filesystem.WithFS(fsys), filesystem.WithTransaction())
+
+		// Assert
+		errs := filesystem.CopyErrors(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, filepath.Join(destdir, "z\x00.txt"), errs[0].Dest)
+		assert.NoDirExists(t, destdir)
+	})
+
+	t.Run("success_rollback", func(t *testing.T) {
+		// Arrange
+		destdir, snapshot, parent := destdir(t)
+
+		// Act
+		tx, err := filesystem.CopyDirTx(srcdir, destdir)
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "a.txt"), filepath.Join(destdir, "a.txt"))
+		err = tx.Rollback()
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, snapshot, destdir)
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.ErrorIs(t, tx.Commit(), filesystem.ErrTransactionDone)
+	})
+
+	t.Run("success_rollback_file_replaced_by_dir", func(t *testing.T) {
+		// Arrange
+		destdir, snapshot, parent := destdir(t)
+		require.NoError(t, os.WriteFile(filepath.Join(destdir, "b"), []byte("old b"), filesystem.RwRR)) // b is a directory in srcdir
+		require.NoError(t, filesystem.CopyDir(destdir, snapshot))
+
+		// Act
+		tx, err := filesystem.CopyDirTx(srcdir, destdir)
+		require.NoError(t, err)
+		assert.DirExists(t, filepath.Join(destdir, "b"))
+		err = tx.Rollback()
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, snapshot, destdir)
+		assert.FileExists(t, filepath.Join(destdir, "b"))
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("success_rollback_backup_other_device", func(t *testing.T) {
+		// Arrange
+		destdir, snapshot, _ := destdir(t)
+		backupdir, err := os.MkdirTemp("/dev/shm", "backup-")
+		if err != nil {
+			t.Skip("no other filesystem available:", err)
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(backupdir) })
+
+		// Act
+		tx, err := filesystem.CopyDirTx(srcdir, destdir, filesystem.WithBackupDir(backupdir))
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "a.txt"), filepath.Join(destdir, "a.txt"))
+		err = tx.Rollback()
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, snapshot, destdir)
+		info, err := os.Stat(filepath.Join(destdir, "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, filesystem.Rw, info.Mode().Perm()) // permissions are kept by copy
+		entries, err := os.ReadDir(backupdir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("success_rollback_parent_not_writable", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Getuid() == 0 {
+			t.Skip("directory permissions don't apply")
+		}
+
+		// Arrange
+		destdir, snapshot, parent := destdir(t)
+		require.NoError(t, os.Chmod(parent, 0o555))
+		t.Cleanup(func() { _ = os.Chmod(parent, filesystem.RwxRxRxRx) })
+
+		// Act
+		tx, err := filesystem.CopyDirTx(srcdir, destdir)
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "a.txt"), filepath.Join(destdir, "a.txt"))
+		err = tx.Rollback()
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualDir(t, snapshot, destdir)
+	})
+
+	t.Run("success_commit", func(t *testing.T) {
+		// Arrange
+		destdir, _, parent := destdir(t)
+
+		// Act
+		tx, err := filesystem.CopyDirTx(srcdir, destdir)
+		require.NoError(t, err)
+		err = tx.Commit()
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "a.txt"), filepath.Join(destdir, "a.txt"))
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "b", "c.txt"), filepath.Join(destdir, "b", "c.txt"))
+		assert.FileExists(t, filepath.Join(destdir, "e.txt"))
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.ErrorIs(t, tx.Rollback(), filesystem.ErrTransactionDone)
+	})
+
+	t.Run("success_with_transaction", func(t *testing.T) {
+		// Arrange
+		destdir, _, parent := destdir(t)
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithTransaction())
+
+		// Assert
+		require.NoError(t, err)
+		tests.AssertEqualFile(t, filepath.Join(srcdir, "d.txt"), filepath.Join(destdir, "d.txt"))
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
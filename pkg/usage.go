@@ -0,0 +1,83 @@
+package filesystem
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// UsageStats represents the disk usage of a set of files and directories.
+type UsageStats struct {
+	// Files is the number of files (symbolic links included).
+	Files int
+	// Dirs is the number of directories.
+	Dirs int
+	// Size is the apparent size in bytes (sum of files sizes).
+	Size int64
+	// Blocks is the number of allocated 512-bytes blocks, only available for OS files on unix platforms.
+	Blocks int64
+}
+
+// add adds info statistics to s.
+func (s *UsageStats) add(info fs.FileInfo) {
+	if info.IsDir() {
+		s.Dirs++
+	} else {
+		s.Files++
+		s.Size += info.Size()
+	}
+	s.Blocks += blocks(info)
+}
+
+// TreeUsage represents the disk usage of a directory tree.
+type TreeUsage struct {
+	UsageStats
+
+	// ByDir contains the usage of each top-level directory (the directory itself included),
+	// files directly inside the root directory are under ".".
+	ByDir map[string]UsageStats
+	// ByExt contains the usage of files by extension (e.g. ".go"), files without extension are under "".
+	ByExt map[string]UsageStats
+}
+
+// Usage returns the disk usage of root directory (root itself excluded).
+//
+// Options like WithFS, WithJoin, WithFilter, WithInclude or WithExclude are taken into account to walk root,
+// meaning the usage is the one CopyDir would copy with the same options.
+func Usage(root string, opts ...FSOption) (*TreeUsage, error) {
+	usage := &TreeUsage{ByDir: map[string]UsageStats{}, ByExt: map[string]UsageStats{}}
+
+	err := Walk(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		usage.add(info)
+
+		top := "."
+		if i := strings.IndexAny(path, "/"+string(filepath.Separator)); i >= 0 {
+			top = path[:i]
+		} else if info.IsDir() {
+			top = path
+		}
+		stats := usage.ByDir[top]
+		stats.add(info)
+		usage.ByDir[top] = stats
+
+		if !info.IsDir() {
+			ext := filepath.Ext(entry.Name())
+			stats := usage.ByExt[ext]
+			stats.add(info)
+			usage.ByExt[ext] = stats
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package filesystem
+
+import "io/fs"
+
+// blocks is not supported outside unix platforms, it always returns 0.
+func blocks(fs.FileInfo) int64 {
+	return 0
+}
@@ -0,0 +1,83 @@
+package filesystem_test
+
+import (
+	"io/fs"
+	"path"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestUsage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":          &fstest.MapFile{Data: []byte("readme")},
+		"Makefile":           &fstest.MapFile{Data: []byte("all:")},
+		"pkg/file.go":        &fstest.MapFile{Data: []byte("package pkg")},
+		"pkg/sub/other.go":   &fstest.MapFile{Data: []byte("package sub")},
+		"docs/index.md":      &fstest.MapFile{Data: []byte("# index")},
+		"docs/empty":         &fstest.MapFile{Mode: fs.ModeDir},
+		"vendor/mod/mod.go":  &fstest.MapFile{Data: []byte("package mod")},
+		"vendor/mod/mod.txt": &fstest.MapFile{Data: []byte("mod")},
+	}
+
+	t.Run("error_root", func(t *testing.T) {
+		// Act
+		_, err := filesystem.Usage("invalid", filesystem.WithFS(fsys))
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		usage, err := filesystem.Usage(".", filesystem.WithFS(fsys), filesystem.WithJoin(path.Join), filesystem.WithExclude("vendor"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, filesystem.UsageStats{Files: 5, Dirs: 4, Size: 39}, usage.UsageStats)
+		assert.Equal(t, map[string]filesystem.UsageStats{
+			".":    {Files: 2, Size: 10},
+			"docs": {Files: 1, Dirs: 2, Size: 7},
+			"pkg":  {Files: 2, Dirs: 2, Size: 22},
+		}, usage.ByDir)
+		assert.Equal(t, map[string]filesystem.UsageStats{
+			"":    {Files: 1, Size: 4},
+			".go": {Files: 2, Size: 22},
+			".md": {Files: 2, Size: 13},
+		}, usage.ByExt)
+	})
+
+	t.Run("success_os_blocks", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		require.NoError(t, filesystem.CopyDir(".", root, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join)))
+
+		// Act
+		usage, err := filesystem.Usage(root)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 7, usage.Files)
+		assert.Equal(t, 6, usage.Dirs)
+		assert.EqualValues(t, 53, usage.Size)
+		vendor := usage.ByDir["vendor"]
+		assert.Equal(t, 2, vendor.Files)
+		assert.Equal(t, 2, vendor.Dirs)
+		assert.EqualValues(t, 14, vendor.Size)
+
+		var blocks int64
+		for _, stats := range usage.ByDir {
+			blocks += stats.Blocks
+		}
+		assert.Equal(t, usage.Blocks, blocks)
+		if runtime.GOOS != "windows" {
+			assert.Positive(t, usage.Blocks)
+			assert.Positive(t, vendor.Blocks)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// blocks returns the number of 512-bytes blocks allocated for info's file, 0 when info doesn't come from OS filesystem.
+func blocks(info fs.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0
+	}
+	return stat.Blocks
+}
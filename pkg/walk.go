@@ -0,0 +1,266 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// Filter represents a function deciding whether a file or a directory must be kept (true) or ignored (false).
+//
+// The path is relative to the walked (or copied) root directory.
+// When a directory is ignored, its whole content is ignored too.
+type Filter func(path string, entry fs.DirEntry) bool
+
+// WithFilter specifies filters to apply in Walk, CopyDir and Watch.
+//
+// A file or a directory is kept only if all filters keep it.
+func WithFilter(filters ...Filter) FSOption {
+	return func(fsOpt *fsOpt) {
+		for _, filter := range filters {
+			if filter != nil {
+				fsOpt.filters = append(fsOpt.filters, filter)
+			}
+		}
+	}
+}
+
+// keep returns true if all filters keep the given path.
+func (o *fsOpt) keep(path string, entry fs.DirEntry) bool {
+	for _, filter := range o.filters {
+		if !filter(path, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithMaxDepth specifies the maximum depth visited by Walk (and watched by Watch), 1 meaning only root directory direct children.
+//
+// A depth of 0 (the default) means there's no limit.
+func WithMaxDepth(depth int) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.maxDepth = depth
+	}
+}
+
+// SymlinkPolicy represents the behavior of Walk when encountering symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SymlinkVisit visits symbolic links as any other file, without following them.
+	SymlinkVisit SymlinkPolicy = iota
+	// SymlinkSkip ignores symbolic links.
+	SymlinkSkip
+	// SymlinkFollow follows symbolic links, visiting their target (and its content in case of a directory)
+	// under the symbolic link path.
+	//
+	// Symbolic links cycles are detected for OS FS, for other FS, WithMaxDepth should be used to avoid infinite walks.
+	SymlinkFollow
+)
+
+// WithSymlinks specifies Walk (and Watch) behavior when encountering symbolic links (default is SymlinkVisit).
+func WithSymlinks(policy SymlinkPolicy) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.symlinks = policy
+	}
+}
+
+// WithPostOrder specifies that Walk must visit directories after their content instead of before.
+func WithPostOrder() FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.postOrder = true
+	}
+}
+
+// WalkFunc is the function called by Walk for each visited file or directory.
+//
+// The path is relative to walked root directory (and joined with WithJoin function).
+// The entry describes the file or directory (symbolic link target in case of SymlinkFollow).
+//
+// When reading a directory fails, the function is called a second time with the directory and the error.
+// When following a symbolic link fails, the function is called with the symbolic link and the error.
+// Returning a non nil error stops the walk, except for fs.SkipDir and fs.SkipAll:
+//   - fs.SkipDir on a directory skips its content (only in pre-order), on a file it skips all remaining files in its directory,
+//   - fs.SkipAll skips all remaining files and directories.
+type WalkFunc func(path string, entry fs.DirEntry, err error) error
+
+// Walk walks recursively the directory root, calling fn for each file or directory inside it (root excluded).
+//
+// Files are walked in lexical order and only files and directories kept by filters (see WithFilter) are visited.
+// See also WithFS, WithJoin, WithMaxDepth, WithSymlinks and WithPostOrder to customize the walk.
+func Walk(root string, fn WalkFunc, opts ...FSOption) error {
+	return walkRoot(newFSOpt(opts...), root, fn)
+}
+
+// walkRoot walks recursively the directory root with already built options (see Walk).
+func walkRoot(o *fsOpt, root string, fn WalkFunc) error {
+	if o.patternErr != nil {
+		return o.patternErr
+	}
+
+	w := &walker{fsOpt: o, fn: fn}
+
+	entries, err := w.fsys.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	var ancestors []fs.FileInfo
+	if w.symlinks == SymlinkFollow {
+		if info, err := fs.Stat(w.fsys, root); err == nil {
+			ancestors = append(ancestors, info)
+		}
+	}
+
+	if err := w.walk(root, "", entries, 1, ancestors); err != nil && !errors.Is(err, fs.SkipAll) {
+		return err
+	}
+	return nil
+}
+
+// walker holds the state of a single Walk call.
+type walker struct {
+	*fsOpt
+
+	fn WalkFunc
+}
+
+// walk visits all given entries of dir, rel being the path of dir relative to walked root.
+func (w *walker) walk(dir, rel string, entries []fs.DirEntry, depth int, ancestors []fs.FileInfo) error {
+	for _, entry := range entries {
+		src := w.join(dir, entry.Name())
+		path := entry.Name()
+		if rel != "" {
+			path = w.join(rel, entry.Name())
+		}
+
+		// handle symbolic links
+		var info fs.FileInfo
+		if entry.Type()&fs.ModeSymlink != 0 {
+			switch w.symlinks {
+			case SymlinkSkip:
+				continue
+			case SymlinkFollow:
+				target, err := fs.Stat(w.fsys, src)
+				if err != nil {
+					if err := w.fn(path, entry, err); err != nil {
+						return skipDir(err)
+					}
+					continue
+				}
+				info = target
+				entry = &followedEntry{FileInfo: target, name: entry.Name()}
+			default:
+			}
+		}
+
+		if !w.keep(path, entry) {
+			continue
+		}
+
+		// handle files
+		if !entry.IsDir() {
+			if err := w.fn(path, entry, nil); err != nil {
+				return skipDir(err)
+			}
+			continue
+		}
+
+		// handle directories
+		if err := w.walkDir(src, path, entry, depth, ancestors, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkDir visits the directory src and its content (depending on max depth and symbolic links cycles).
+func (w *walker) walkDir(src, path string, entry fs.DirEntry, depth int, ancestors []fs.FileInfo, info fs.FileInfo) error {
+	if !w.postOrder {
+		if err := w.fn(path, entry, nil); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	// ancestors are only needed to detect symbolic links cycles
+	if w.symlinks == SymlinkFollow && info == nil {
+		info, _ = entry.Info()
+	}
+
+	if w.descend(depth, ancestors, info) {
+		if w.symlinks == SymlinkFollow {
+			ancestors = append(ancestors[:len(ancestors):len(ancestors)], info)
+		}
+
+		entries, err := w.fsys.ReadDir(src)
+		if err != nil {
+			// error is reported in place of post-order visit
+			return skipDir(w.fn(path, entry, err))
+		}
+		if err := w.walk(src, path, entries, depth+1, ancestors); err != nil {
+			return err
+		}
+	}
+
+	if w.postOrder {
+		if err := w.fn(path, entry, nil); err != nil {
+			return skipDir(err)
+		}
+	}
+	return nil
+}
+
+// descend returns true if a directory at depth must be walked.
+//
+// It returns false when max depth is reached or when the directory (info) is one of its ancestors
+// (symbolic link cycle, only checked with SymlinkFollow).
+func (w *walker) descend(depth int, ancestors []fs.FileInfo, info fs.FileInfo) bool {
+	if w.maxDepth > 0 && depth >= w.maxDepth {
+		return false
+	}
+	if info == nil {
+		return true
+	}
+	for _, ancestor := range ancestors {
+		if ancestor != nil && os.SameFile(ancestor, info) {
+			return false
+		}
+	}
+	return true
+}
+
+// skipDir returns nil when err is fs.SkipDir (skipping the current directory remaining entries), err otherwise.
+func skipDir(err error) error {
+	if errors.Is(err, fs.SkipDir) {
+		return nil
+	}
+	return err
+}
+
+// followedEntry represents the target of a followed symbolic link, named after the symbolic link.
+type followedEntry struct {
+	fs.FileInfo
+
+	name string
+}
+
+var _ fs.DirEntry = (*followedEntry)(nil) // ensure interface is implemented
+
+// Name returns the symbolic link name.
+func (e *followedEntry) Name() string {
+	return e.name
+}
+
+// Type returns the type bits of the symbolic link target.
+func (e *followedEntry) Type() fs.FileMode {
+	return e.Mode().Type()
+}
+
+// Info returns the FileInfo of the symbolic link target.
+func (e *followedEntry) Info() (fs.FileInfo, error) {
+	return e.FileInfo, nil
+}
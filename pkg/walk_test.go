@@ -0,0 +1,190 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestWalk(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":       &fstest.MapFile{Data: []byte("a")},
+		"b/c.txt":     &fstest.MapFile{Data: []byte("c")},
+		"b/d/e.txt":   &fstest.MapFile{Data: []byte("e")},
+		"f/g.txt":     &fstest.MapFile{Data: []byte("g")},
+		"h.go":        &fstest.MapFile{Data: []byte("h")},
+		"empty":       &fstest.MapFile{Mode: fs.ModeDir},
+		"b/d/f/i.txt": &fstest.MapFile{Data: []byte("i")},
+	}
+
+	// walk returns all visited paths (directories suffixed with /)
+	walk := func(t *testing.T, fn filesystem.WalkFunc, opts ...filesystem.FSOption) ([]string, error) {
+		t.Helper()
+		var paths []string
+		err := filesystem.Walk(".", func(path string, entry fs.DirEntry, err error) error {
+			require.NoError(t, err)
+			if entry.IsDir() {
+				paths = append(paths, path+"/")
+			} else {
+				paths = append(paths, path)
+			}
+			if fn != nil {
+				return fn(path, entry, err)
+			}
+			return nil
+		}, append(opts, filesystem.WithFS(fsys), filesystem.WithJoin(path.Join))...)
+		return paths, err
+	}
+
+	t.Run("error_root", func(t *testing.T) {
+		// Act
+		err := filesystem.Walk("invalid", func(string, fs.DirEntry, error) error { return nil }, filesystem.WithFS(fsys))
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("error_stop", func(t *testing.T) {
+		// Arrange
+		stop := errors.New("stop")
+
+		// Act
+		paths, err := walk(t, func(path string, _ fs.DirEntry, _ error) error {
+			if path == "b/d/e.txt" {
+				return stop
+			}
+			return nil
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, []string{"a.txt", "b/", "b/c.txt", "b/d/", "b/d/e.txt"}, paths)
+	})
+
+	t.Run("success_pre_order", func(t *testing.T) {
+		// Act
+		paths, err := walk(t, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "b/", "b/c.txt", "b/d/", "b/d/e.txt", "b/d/f/", "b/d/f/i.txt", "empty/", "f/", "f/g.txt", "h.go"}, paths)
+	})
+
+	t.Run("success_post_order", func(t *testing.T) {
+		// Act
+		paths, err := walk(t, nil, filesystem.WithPostOrder())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "b/c.txt", "b/d/e.txt", "b/d/f/i.txt", "b/d/f/", "b/d/", "b/", "empty/", "f/g.txt", "f/", "h.go"}, paths)
+	})
+
+	t.Run("success_filters", func(t *testing.T) {
+		// Arrange
+		noB := func(path string, _ fs.DirEntry) bool { return path != "b" }
+		noGo := func(path string, entry fs.DirEntry) bool { return entry.IsDir() || filepath.Ext(path) != ".go" }
+
+		// Act
+		paths, err := walk(t, nil, filesystem.WithFilter(noB, nil), filesystem.WithFilter(noGo))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "empty/", "f/", "f/g.txt"}, paths)
+	})
+
+	t.Run("success_max_depth", func(t *testing.T) {
+		// Act
+		paths, err := walk(t, nil, filesystem.WithMaxDepth(2))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "b/", "b/c.txt", "b/d/", "empty/", "f/", "f/g.txt", "h.go"}, paths)
+	})
+
+	t.Run("success_skip_dir", func(t *testing.T) {
+		// Act
+		paths, err := walk(t, func(path string, _ fs.DirEntry, _ error) error {
+			switch path {
+			case "b/d", "f/g.txt":
+				return fs.SkipDir
+			case "h.go":
+				return fs.SkipAll
+			}
+			return nil
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "b/", "b/c.txt", "b/d/", "empty/", "f/", "f/g.txt", "h.go"}, paths)
+	})
+}
+
+func TestWalk_Symlinks(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "dir", "sub"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "dir", "sub", "file.txt"), []byte("file"), filesystem.RwRR))
+	if err := os.Symlink(filepath.Join(root, "dir", "sub"), filepath.Join(root, "link")); err != nil {
+		t.Skip("symbolic links not supported:", err)
+	}
+	require.NoError(t, os.Symlink(filepath.Join(root, "dir"), filepath.Join(root, "dir", "sub", "cycle")))
+	require.NoError(t, os.Symlink(filepath.Join(root, "invalid"), filepath.Join(root, "broken")))
+
+	// walk returns all visited paths (directories suffixed with /, symbolic links with @ and errors with !)
+	walk := func(t *testing.T, opts ...filesystem.FSOption) []string {
+		t.Helper()
+		var paths []string
+		err := filesystem.Walk(root, func(path string, entry fs.DirEntry, err error) error {
+			path = filepath.ToSlash(path)
+			switch {
+			case err != nil:
+				paths = append(paths, path+"!")
+			case entry.Type()&fs.ModeSymlink != 0:
+				paths = append(paths, path+"@")
+			case entry.IsDir():
+				paths = append(paths, path+"/")
+			default:
+				paths = append(paths, path)
+			}
+			return nil
+		}, opts...)
+		require.NoError(t, err)
+		return paths
+	}
+
+	t.Run("success_visit", func(t *testing.T) {
+		// Act
+		paths := walk(t)
+
+		// Assert
+		assert.Equal(t, []string{"broken@", "dir/", "dir/sub/", "dir/sub/cycle@", "dir/sub/file.txt", "link@"}, paths)
+	})
+
+	t.Run("success_skip", func(t *testing.T) {
+		// Act
+		paths := walk(t, filesystem.WithSymlinks(filesystem.SymlinkSkip))
+
+		// Assert
+		assert.Equal(t, []string{"dir/", "dir/sub/", "dir/sub/file.txt"}, paths)
+	})
+
+	t.Run("success_follow", func(t *testing.T) {
+		// Act
+		paths := walk(t, filesystem.WithSymlinks(filesystem.SymlinkFollow))
+
+		// Assert
+		assert.Equal(t, []string{
+			"broken!",
+			"dir/", "dir/sub/", "dir/sub/cycle/", "dir/sub/file.txt",
+			"link/", "link/cycle/", "link/cycle/sub/", "link/file.txt",
+		}, paths)
+	})
+}
@@ -0,0 +1,277 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	// defaultDebounce is the default duration without any change waited by Watch before reporting changes.
+	defaultDebounce = 100 * time.Millisecond
+
+	// defaultPollInterval is the default interval between two scans of a watched tree when polling.
+	defaultPollInterval = 500 * time.Millisecond
+)
+
+// EventOp represents the kind of change reported by Watch.
+type EventOp int
+
+const (
+	// EventCreate is reported when a file or directory is created.
+	EventCreate EventOp = iota + 1
+	// EventWrite is reported when a file content (or a file or directory mode) is modified.
+	EventWrite
+	// EventRemove is reported when a file or directory is removed.
+	EventRemove
+	// EventRename is reported when a file or directory is renamed (or moved) inside the watched tree.
+	EventRename
+)
+
+// String returns the name of the event operation.
+func (op EventOp) String() string {
+	switch op {
+	case EventCreate:
+		return "create"
+	case EventWrite:
+		return "write"
+	case EventRemove:
+		return "remove"
+	case EventRename:
+		return "rename"
+	default:
+		return "unknown"
+	}
+}
+
+// Event represents a change reported by Watch.
+type Event struct {
+	// Op is the kind of change.
+	Op EventOp
+
+	// Path is the path of the changed file or directory relative to watched root directory (and joined with WithJoin function).
+	Path string
+
+	// OldPath is the path before renaming of the file or directory, only set for EventRename.
+	OldPath string
+}
+
+// WatchFunc is the function called by Watch with each batch of changes.
+type WatchFunc func(events []Event)
+
+// WithDebounce specifies the duration without any change Watch waits for before reporting a batch of changes (100ms by default).
+//
+// A duration of 0 (or less) reports each change as soon as it happens.
+func WithDebounce(debounce time.Duration) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.debounce = debounce
+	}
+}
+
+// WithPollInterval specifies the interval between two scans of the watched tree when Watch polls for changes (500ms by default).
+func WithPollInterval(interval time.Duration) FSOption {
+	return func(fsOpt *fsOpt) {
+		fsOpt.pollInterval = interval
+	}
+}
+
+// Watch watches recursively the directory root for changes (root excluded), calling fn with each batch of changes
+// until ctx is done (in which case it returns nil) or an error occurs (e.g. root is removed).
+// In both cases, the pending batch of changes is reported before returning.
+//
+// Changes are reported in order, bursts of changes being debounced (see WithDebounce) and duplicated changes merged
+// (e.g. a write on a file just created is reported as a single EventCreate).
+// Only files and directories kept by filters (see WithFilter, WithInclude and WithExclude) are watched,
+// WithMaxDepth and WithSymlinks are taken into account too.
+//
+// On Linux with the OS FS, changes are received from inotify, otherwise root tree is scanned periodically (see WithPollInterval).
+// When inotify queue overflows, root tree is scanned again to report the lost changes.
+// When polling, renames are only detected on the OS FS, other FS report them as an EventRemove and an EventCreate.
+func Watch(ctx context.Context, root string, fn WatchFunc, opts ...FSOption) error {
+	o := newFSOpt(opts...)
+	if o.patternErr != nil {
+		return o.patternErr
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan Event)
+	emit := func(event Event) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		err := notify(ctx, root, o, emit)
+		if errors.Is(err, errors.ErrUnsupported) {
+			err = poll(ctx, root, o, emit)
+		}
+		errc <- err
+	}()
+
+	var (
+		batch []Event
+		timer = time.NewTimer(o.debounce)
+	)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if len(batch) > 0 {
+				fn(batch)
+			}
+			return nil
+		case err := <-errc:
+			if len(batch) > 0 {
+				fn(batch)
+			}
+			return err
+		case event := <-events:
+			batch = merge(batch, event)
+			if o.debounce == 0 {
+				fn(batch)
+				batch = nil
+				continue
+			}
+			// stop and drain timer before reset since a tick may be pending (go < 1.23 semantics)
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(o.debounce)
+		case <-timer.C:
+			if len(batch) > 0 {
+				fn(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+// merge adds event to batch, unless it's redundant with an event already in batch for the same path
+// (same operation or a write after a creation).
+func merge(batch []Event, event Event) []Event {
+	for i := len(batch) - 1; i >= 0; i-- {
+		previous := batch[i]
+		if previous.Path != event.Path {
+			continue
+		}
+		if previous == event || (previous.Op == EventCreate && event.Op == EventWrite) {
+			return batch
+		}
+		break // only the last event of a path matters
+	}
+	return append(batch, event)
+}
+
+// state represents a file or directory as seen during a scan of the watched tree.
+type state struct {
+	info fs.FileInfo
+	dir  bool
+}
+
+// changed returns true if the file or directory changed between s and other.
+func (s state) changed(other state) bool {
+	if s.dir != other.dir {
+		return true
+	}
+	if s.info == nil || other.info == nil {
+		return false
+	}
+	if s.info.Mode() != other.info.Mode() {
+		return true
+	}
+	return !s.dir && (s.info.Size() != other.info.Size() || !s.info.ModTime().Equal(other.info.ModTime()))
+}
+
+// scan returns the state of all files and directories in root tree kept by filters, by relative path,
+// and all relative paths in walk order.
+func scan(root string, o *fsOpt) (map[string]state, []string, error) {
+	states := map[string]state{}
+	var paths []string
+	err := walkRoot(o, root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable directories are ignored, they may have been removed during scan
+		}
+		info, _ := entry.Info()
+		states[path] = state{info: info, dir: entry.IsDir()}
+		paths = append(paths, path)
+		return nil
+	})
+	return states, paths, err
+}
+
+// poll scans periodically the root tree and emits the changes between two scans until ctx is done.
+func poll(ctx context.Context, root string, o *fsOpt, emit func(Event)) error {
+	previous, previousPaths, err := scan(root, o)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, paths, err := scan(root, o)
+		if err != nil {
+			return err
+		}
+		for _, event := range changes(previous, previousPaths, current, paths) {
+			emit(event)
+		}
+		previous, previousPaths = current, paths
+	}
+}
+
+// changes returns the events to go from previous states to current states,
+// previousPaths and paths being their respective paths in walk order.
+func changes(previous map[string]state, previousPaths []string, current map[string]state, paths []string) []Event {
+	var removed []string
+	for _, path := range previousPaths {
+		if _, ok := current[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+
+	var events []Event
+	for _, path := range paths {
+		state := current[path]
+		if old, ok := previous[path]; ok {
+			if old.changed(state) {
+				events = append(events, Event{Op: EventWrite, Path: path})
+			}
+			continue
+		}
+
+		// a new path is a rename when it's the same OS file as a removed one
+		renamed := slices.IndexFunc(removed, func(oldPath string) bool {
+			old := previous[oldPath]
+			return old.info != nil && state.info != nil && os.SameFile(old.info, state.info)
+		})
+		if renamed < 0 {
+			events = append(events, Event{Op: EventCreate, Path: path})
+			continue
+		}
+		events = append(events, Event{Op: EventRename, Path: path, OldPath: removed[renamed]})
+		removed = slices.Delete(removed, renamed, renamed+1)
+	}
+
+	for _, path := range removed {
+		events = append(events, Event{Op: EventRemove, Path: path})
+	}
+	return events
+}
@@ -0,0 +1,341 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// inotifyMask is the mask of inotify events watched on each directory.
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ONLYDIR
+
+// notify watches root tree with inotify and emits its changes until ctx is done.
+//
+// It returns errors.ErrUnsupported when fsys isn't the OS FS or when inotify cannot be initialized.
+func notify(ctx context.Context, root string, o *fsOpt, emit func(Event)) error {
+	if o.fsys != OS() {
+		return errors.ErrUnsupported
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return fmt.Errorf("failed to initialize inotify: %w: %w", errors.ErrUnsupported, err)
+	}
+	// a non blocking file is handled by go runtime poller, closing it unblocks any pending read
+	file := os.NewFile(uintptr(fd), "inotify")
+	defer file.Close()
+	stop := context.AfterFunc(ctx, func() { file.Close() })
+	defer stop()
+
+	n := &notifier{fsOpt: o, fd: fd, root: root, watches: map[int]watched{}, moves: map[uint32]string{}, emit: emit}
+	if err := n.watch(root, "", 0, false); err != nil {
+		return err
+	}
+	// states are scanned after watches are added to not miss any change between both
+	if n.states, _, err = scan(root, o); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		count, err := file.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read inotify events: %w", err)
+		}
+		if err := n.handle(buf[:count]); err != nil {
+			return err
+		}
+	}
+}
+
+// watched represents a directory watched with inotify.
+type watched struct {
+	dir   string
+	path  string
+	depth int
+}
+
+// notifier holds the state of a single inotify watch.
+type notifier struct {
+	*fsOpt
+
+	fd      int
+	root    string
+	watches map[int]watched
+	moves   map[uint32]string
+	states  map[string]state
+	emit    func(Event)
+}
+
+// report records the state of event path (used to compute changes when inotify queue overflows) and emits event.
+func (n *notifier) report(event Event) {
+	switch event.Op {
+	case EventRemove:
+		for path := range n.states {
+			if inside(path, event.Path) {
+				delete(n.states, path)
+			}
+		}
+	case EventRename:
+		moved := map[string]state{}
+		for path, state := range n.states {
+			if inside(path, event.OldPath) {
+				moved[event.Path+strings.TrimPrefix(path, event.OldPath)] = state
+				delete(n.states, path)
+			}
+		}
+		maps.Copy(n.states, moved)
+	default:
+	}
+	if event.Op != EventRemove {
+		if entry, ok := n.entry(n.join(n.root, event.Path), filepath.Base(event.Path), false); ok {
+			info, _ := entry.Info()
+			n.states[event.Path] = state{info: info, dir: entry.IsDir()}
+		}
+	}
+	n.emit(event)
+}
+
+// rescan scans root tree again and emits the changes since last reported states,
+// it's used when inotify queue overflows since events are lost.
+func (n *notifier) rescan() error {
+	clear(n.moves)
+	// directories created in the meantime must be watched
+	if err := n.watch(n.root, "", 0, false); err != nil {
+		return err
+	}
+	current, paths, err := scan(n.root, n.fsOpt)
+	if err != nil {
+		return err
+	}
+
+	// previous paths are sorted in walk order, i.e. lexically by path elements
+	previousPaths := make([]string, 0, len(n.states))
+	for path := range n.states {
+		previousPaths = append(previousPaths, path)
+	}
+	slices.SortFunc(previousPaths, func(a, b string) int {
+		return slices.Compare(strings.Split(a, string(filepath.Separator)), strings.Split(b, string(filepath.Separator)))
+	})
+
+	for _, event := range changes(n.states, previousPaths, current, paths) {
+		n.emit(event)
+	}
+	n.states = current
+	return nil
+}
+
+// rel returns the relative path of name inside the directory at relative path dir (empty for root).
+func (n *notifier) rel(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return n.join(dir, name)
+}
+
+// inside returns true if path is inside parent (or equal to it), any path being inside root (empty parent).
+func inside(path, parent string) bool {
+	return parent == "" || path == parent || strings.HasPrefix(path, parent+string(filepath.Separator))
+}
+
+// watch adds an inotify watch on dir (and its subdirectories kept by filters),
+// emitting an EventCreate for each of its files and directories when created is true.
+func (n *notifier) watch(dir, path string, depth int, created bool) error {
+	wd, err := syscall.InotifyAddWatch(n.fd, dir, inotifyMask)
+	if err != nil {
+		return &fs.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	// the same directory may be watched again when moved, but not inside itself (symbolic links cycle)
+	if w, ok := n.watches[wd]; ok && w.path != path && inside(path, w.path) {
+		return nil
+	}
+	n.watches[wd] = watched{dir: dir, path: path, depth: depth}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		src := n.join(dir, entry.Name())
+		rel := n.rel(path, entry.Name())
+
+		entry, ok := n.entry(src, entry.Name(), entry.IsDir())
+		if !ok || !n.keep(rel, entry) {
+			continue
+		}
+		if created {
+			n.report(Event{Op: EventCreate, Path: rel})
+		}
+		if entry.IsDir() && (n.maxDepth <= 0 || depth+1 < n.maxDepth) {
+			_ = n.watch(src, rel, depth+1, created) // directory may have been removed in the meantime
+		}
+	}
+	return nil
+}
+
+// unwatch removes inotify watches of the directory at relative path and its subdirectories.
+func (n *notifier) unwatch(path string) {
+	for wd, w := range n.watches {
+		if w.path != "" && inside(w.path, path) {
+			_, _ = syscall.InotifyRmWatch(n.fd, uint32(wd))
+			delete(n.watches, wd)
+		}
+	}
+}
+
+// entry returns the entry of src according to symbolic links policy, false when it must be ignored.
+//
+// When src doesn't exist anymore, a removedEntry is returned.
+func (n *notifier) entry(src, name string, dir bool) (fs.DirEntry, bool) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return &removedEntry{name: name, dir: dir}, true
+	}
+	entry := fs.FileInfoToDirEntry(info)
+	if info.Mode()&fs.ModeSymlink == 0 {
+		return entry, true
+	}
+
+	switch n.symlinks {
+	case SymlinkSkip:
+		return nil, false
+	case SymlinkFollow:
+		if target, err := os.Stat(src); err == nil {
+			return &followedEntry{FileInfo: target, name: name}, true
+		}
+	default:
+	}
+	return entry, true
+}
+
+// handle decodes all inotify events of buf and emits the associated changes.
+//
+// Moves without a peer event in buf are reported as removals.
+func (n *notifier) handle(buf []byte) error {
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+		mask := binary.NativeEndian.Uint32(buf[offset+4:])
+		cookie := binary.NativeEndian.Uint32(buf[offset+8:])
+		length := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+
+		start := offset + syscall.SizeofInotifyEvent
+		offset = start + length
+		name := string(bytes.TrimRight(buf[start:min(offset, len(buf))], "\x00"))
+
+		if err := n.event(int(wd), mask, cookie, name); err != nil {
+			return err
+		}
+	}
+
+	for cookie, path := range n.moves {
+		n.report(Event{Op: EventRemove, Path: path})
+		n.unwatch(path)
+		delete(n.moves, cookie)
+	}
+	return nil
+}
+
+// event handles a single inotify event.
+func (n *notifier) event(wd int, mask, cookie uint32, name string) error {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return n.rescan()
+	}
+	w, ok := n.watches[wd]
+	if !ok {
+		return nil
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(n.watches, wd)
+		if w.path == "" {
+			return &fs.PathError{Op: "watch", Path: n.root, Err: fs.ErrNotExist}
+		}
+		return nil
+	}
+	if name == "" {
+		return nil // changes of watched directories themselves are reported by their parent
+	}
+
+	path := n.rel(w.path, name)
+	src := n.join(w.dir, name)
+	entry, ok := n.entry(src, name, mask&syscall.IN_ISDIR != 0)
+	if !ok || !n.keep(path, entry) {
+		// a file moved to an ignored path is removed from watched ones
+		if old, ok := n.moves[cookie]; ok && mask&syscall.IN_MOVED_TO != 0 {
+			delete(n.moves, cookie)
+			n.report(Event{Op: EventRemove, Path: old})
+			n.unwatch(old)
+		}
+		return nil
+	}
+	descend := entry.IsDir() && (n.maxDepth <= 0 || w.depth+1 < n.maxDepth)
+
+	switch {
+	case mask&syscall.IN_CREATE != 0:
+		n.report(Event{Op: EventCreate, Path: path})
+		if descend {
+			_ = n.watch(src, path, w.depth+1, true) // directory may have been removed in the meantime
+		}
+	case mask&(syscall.IN_MODIFY|syscall.IN_ATTRIB) != 0:
+		n.report(Event{Op: EventWrite, Path: path})
+	case mask&syscall.IN_DELETE != 0:
+		n.report(Event{Op: EventRemove, Path: path})
+	case mask&syscall.IN_MOVED_FROM != 0:
+		n.moves[cookie] = path
+	case mask&syscall.IN_MOVED_TO != 0:
+		old, renamed := n.moves[cookie]
+		delete(n.moves, cookie)
+		if renamed {
+			n.report(Event{Op: EventRename, Path: path, OldPath: old})
+		} else {
+			n.report(Event{Op: EventCreate, Path: path})
+		}
+		if descend {
+			_ = n.watch(src, path, w.depth+1, !renamed) // directory may have been removed in the meantime
+		}
+	}
+	return nil
+}
+
+// removedEntry represents a file or directory removed before it could be read.
+type removedEntry struct {
+	name string
+	dir  bool
+}
+
+var _ fs.DirEntry = (*removedEntry)(nil) // ensure interface is implemented
+
+// Name returns the name of the removed file or directory.
+func (e *removedEntry) Name() string {
+	return e.name
+}
+
+// IsDir returns true if the removed entry was a directory.
+func (e *removedEntry) IsDir() bool {
+	return e.dir
+}
+
+// Type returns the type bits of the removed entry (only fs.ModeDir can be known).
+func (e *removedEntry) Type() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+// Info always fails since the entry doesn't exist anymore.
+func (e *removedEntry) Info() (fs.FileInfo, error) {
+	return nil, &fs.PathError{Op: "lstat", Path: e.name, Err: fs.ErrNotExist}
+}
@@ -0,0 +1,80 @@
+package filesystem_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestWatch_Overflow(t *testing.T) {
+	// Arrange
+	content, err := os.ReadFile("/proc/sys/fs/inotify/max_queued_events")
+	require.NoError(t, err)
+	limit, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	require.NoError(t, err)
+	if limit > 100_000 {
+		t.Skip("inotify queue is too large to overflow:", limit)
+	}
+
+	root := t.TempDir()
+	r := &recorder{}
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	fn := func(events []filesystem.Event) {
+		// events aren't read while the first batch is handled, which fills inotify queue
+		once.Do(func() {
+			close(blocked)
+			<-release
+		})
+		r.record(events)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- filesystem.Watch(ctx, root, fn, filesystem.WithDebounce(0)) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-errc)
+	})
+	require.Eventually(t, func() bool {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "probe"), nil, filesystem.RwRR))
+		select {
+		case <-blocked:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Act
+	count := limit + 100
+	for i := range count {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "file-"+strconv.Itoa(i)), nil, filesystem.RwRR))
+	}
+	close(release)
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		created := map[string]bool{}
+		for _, path := range r.paths() {
+			created[path] = true
+		}
+		for i := range count {
+			if !created["file-"+strconv.Itoa(i)] {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"context"
+	"errors"
+)
+
+// notify is not supported outside Linux, changes are always polled.
+func notify(context.Context, string, *fsOpt, func(Event)) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,226 @@
+package filesystem_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+	"github.com/kilianpaquier/filesystem/pkg/tests"
+)
+
+// recorder records all events reported by Watch and the number of batches they were reported in.
+type recorder struct {
+	mutex   sync.Mutex
+	events  []filesystem.Event
+	batches int
+}
+
+func (r *recorder) record(events []filesystem.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, events...)
+	r.batches++
+}
+
+// reset forgets all recorded events and batches.
+func (r *recorder) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = nil
+	r.batches = 0
+}
+
+// recorded returns all recorded events and the number of batches they were reported in.
+func (r *recorder) recorded() ([]filesystem.Event, int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.events), r.batches
+}
+
+func (r *recorder) has(event filesystem.Event) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Contains(r.events, event)
+}
+
+func (r *recorder) paths() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	paths := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		paths = append(paths, event.Path)
+	}
+	return paths
+}
+
+// watch starts Watch on root and waits for it to report changes (probe files being created until one is reported).
+func watch(t *testing.T, root string, opts ...filesystem.FSOption) *recorder {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &recorder{}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- filesystem.Watch(ctx, root, r.record, append(opts, filesystem.WithDebounce(10*time.Millisecond), filesystem.WithPollInterval(20*time.Millisecond))...)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-errc)
+	})
+
+	probe := 0
+	require.Eventually(t, func() bool {
+		if slices.ContainsFunc(r.paths(), func(path string) bool { return strings.HasPrefix(path, "probe-") }) {
+			return true
+		}
+		probe++
+		require.NoError(t, os.WriteFile(filepath.Join(root, "probe-"+strconv.Itoa(probe)), nil, filesystem.RwRR))
+		return false
+	}, 5*time.Second, 50*time.Millisecond)
+	return r
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("error_root_not_exists", func(t *testing.T) {
+		// Act
+		err := filesystem.Watch(context.Background(), filepath.Join(t.TempDir(), "invalid"), func([]filesystem.Event) {})
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	scenario := func(t *testing.T, opts ...filesystem.FSOption) {
+		t.Helper()
+
+		// Arrange
+		root := t.TempDir()
+		r := watch(t, root, append(opts, filesystem.WithExclude("*.tmp"))...)
+		src := filepath.Join(root, "file.txt")
+		dest := filepath.Join(root, "renamed.txt")
+
+		// Act & Assert
+		require.NoError(t, os.WriteFile(filepath.Join(root, "ignored.tmp"), []byte("ignored"), filesystem.RwRR))
+		require.NoError(t, os.WriteFile(src, []byte("hey"), filesystem.RwRR))
+		assert.Eventually(t, func() bool { return r.has(filesystem.Event{Op: filesystem.EventCreate, Path: "file.txt"}) }, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, os.WriteFile(src, []byte("hey file"), filesystem.RwRR))
+		assert.Eventually(t, func() bool { return r.has(filesystem.Event{Op: filesystem.EventWrite, Path: "file.txt"}) }, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, os.Rename(src, dest))
+		assert.Eventually(t, func() bool {
+			return r.has(filesystem.Event{Op: filesystem.EventRename, Path: "renamed.txt", OldPath: "file.txt"})
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, os.Mkdir(filepath.Join(root, "dir"), filesystem.RwxRxRxRx))
+		assert.Eventually(t, func() bool { return r.has(filesystem.Event{Op: filesystem.EventCreate, Path: "dir"}) }, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, os.WriteFile(filepath.Join(root, "dir", "file.txt"), []byte("hey file"), filesystem.RwRR))
+		nested := filepath.Join("dir", "file.txt")
+		assert.Eventually(t, func() bool { return r.has(filesystem.Event{Op: filesystem.EventCreate, Path: nested}) }, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, os.Remove(dest))
+		assert.Eventually(t, func() bool { return r.has(filesystem.Event{Op: filesystem.EventRemove, Path: "renamed.txt"}) }, 5*time.Second, 10*time.Millisecond)
+
+		assert.NotContains(t, r.paths(), "ignored.tmp")
+	}
+
+	t.Run("success_os", func(t *testing.T) {
+		scenario(t)
+	})
+
+	t.Run("success_poll", func(t *testing.T) {
+		// any FS other than OS one is polled
+		scenario(t, filesystem.WithFS(tests.NewFaultFS(filesystem.OS())))
+	})
+}
+
+func TestWatch_Debounce(t *testing.T) {
+	const debounce = 300 * time.Millisecond
+
+	scenario := func(t *testing.T, opts ...filesystem.FSOption) {
+		t.Helper()
+
+		// Arrange
+		root := t.TempDir()
+		r := &recorder{}
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() {
+			opts := append(opts, filesystem.WithDebounce(debounce), filesystem.WithPollInterval(20*time.Millisecond))
+			errc <- filesystem.Watch(ctx, root, r.record, opts...)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			assert.NoError(t, <-errc)
+		})
+
+		// probes are created slower than debounce to be reported
+		probe := 0
+		require.Eventually(t, func() bool {
+			if _, batches := r.recorded(); batches > 0 {
+				return true
+			}
+			probe++
+			require.NoError(t, os.WriteFile(filepath.Join(root, "probe-"+strconv.Itoa(probe)), nil, filesystem.RwRR))
+			return false
+		}, 10*time.Second, 2*debounce)
+		time.Sleep(2 * debounce) // wait for all probes to be reported
+		r.reset()
+		src := filepath.Join(root, "file.txt")
+
+		// Act
+		require.NoError(t, os.WriteFile(src, []byte("hey"), filesystem.RwRR))
+		for i := range 10 {
+			require.NoError(t, os.WriteFile(src, []byte("hey file "+strconv.Itoa(i)), filesystem.RwRR))
+		}
+
+		// Assert
+		assert.Eventually(t, func() bool {
+			_, batches := r.recorded()
+			return batches > 0
+		}, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(2 * debounce) // no other batch must be reported
+		events, batches := r.recorded()
+		assert.Equal(t, 1, batches)
+		assert.Equal(t, []filesystem.Event{{Op: filesystem.EventCreate, Path: "file.txt"}}, events)
+	}
+
+	t.Run("success_os", func(t *testing.T) {
+		scenario(t)
+	})
+
+	t.Run("success_poll", func(t *testing.T) {
+		scenario(t, filesystem.WithFS(tests.NewFaultFS(filesystem.OS())))
+	})
+
+	t.Run("success_flush_on_done", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		r := &recorder{}
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() {
+			errc <- filesystem.Watch(ctx, root, r.record, filesystem.WithDebounce(time.Hour), filesystem.WithPollInterval(20*time.Millisecond))
+		}()
+		time.Sleep(debounce) // wait for watch to start
+		require.NoError(t, os.WriteFile(filepath.Join(root, "file.txt"), []byte("hey"), filesystem.RwRR))
+		time.Sleep(debounce) // wait for the change to be received
+
+		// Act
+		cancel()
+
+		// Assert
+		require.NoError(t, <-errc)
+		assert.True(t, r.has(filesystem.Event{Op: filesystem.EventCreate, Path: "file.txt"}))
+	})
+}
@@ -0,0 +1,45 @@
+package filesystem
+
+import "strings"
+
+// WithXattrs specifies that CopyFile and CopyDir must copy extended attributes of OS files and directories (only supported on Linux).
+//
+// Only attributes in given namespaces (e.g. "user", "trusted", "security")
+// or matching given full names (e.g. "system.posix_acl_access" for POSIX ACLs) are copied.
+// When no namespace is given, only "user" namespace is copied.
+//
+// POSIX access ACLs ("system.posix_acl_access") are copied after permissions are applied since they define them too
+// (the ACL mask being the group permission bits), meaning a copied access ACL takes precedence over WithPerm and WithDirPerm.
+//
+// Attributes rejected by destination filesystem because not supported (e.g. some namespaces on tmpfs) are skipped.
+func WithXattrs(namespaces ...string) FSOption {
+	return func(fsOpt *fsOpt) {
+		if len(namespaces) == 0 {
+			namespaces = []string{"user"}
+		}
+		fsOpt.xattrs = namespaces
+	}
+}
+
+// aclAccess is the extended attribute holding POSIX access ACL.
+const aclAccess = "system.posix_acl_access"
+
+// xattrsBeforeChmod returns true if the attribute name must be copied before applying permissions.
+func (o *fsOpt) xattrsBeforeChmod(name string) bool {
+	return name != aclAccess && allowedXattr(name, o.xattrs)
+}
+
+// xattrsAfterChmod returns true if the attribute name must be copied after applying permissions.
+func (o *fsOpt) xattrsAfterChmod(name string) bool {
+	return name == aclAccess && allowedXattr(name, o.xattrs)
+}
+
+// allowedXattr returns true if the attribute name is part of one of allowed namespaces or is exactly one of them.
+func allowedXattr(name string, allowed []string) bool {
+	for _, namespace := range allowed {
+		if name == namespace || strings.HasPrefix(name, namespace+".") {
+			return true
+		}
+	}
+	return false
+}
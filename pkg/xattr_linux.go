@@ -0,0 +1,82 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// copyXattrs copies all extended attributes kept by keep from src to dest.
+func copyXattrs(src, dest string, keep func(name string) bool) error {
+	names, err := listXattrs(src)
+	if errors.Is(err, syscall.ENOTSUP) {
+		return nil // source filesystem doesn't support extended attributes
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list extended attributes of %s: %w", src, err)
+	}
+
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		if !keep(name) {
+			continue
+		}
+
+		value, err := getXattr(src, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read extended attribute %s of %s: %w", name, src, err))
+			continue
+		}
+		if err := syscall.Setxattr(dest, name, value, 0); err != nil && !errors.Is(err, syscall.ENOTSUP) {
+			errs = append(errs, fmt.Errorf("failed to write extended attribute %s of %s: %w", name, dest, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listXattrs returns the names of all extended attributes of name.
+func listXattrs(name string) ([]string, error) {
+	buf, err := readXattr(func(dest []byte) (int, error) { return syscall.Listxattr(name, dest) })
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, bytes.Count(buf, []byte{0}))
+	for _, attr := range bytes.Split(buf, []byte{0}) {
+		if len(attr) > 0 {
+			names = append(names, string(attr))
+		}
+	}
+	return names, nil
+}
+
+// getXattr returns the value of extended attribute attr of name.
+func getXattr(name, attr string) ([]byte, error) {
+	return readXattr(func(dest []byte) (int, error) { return syscall.Getxattr(name, attr, dest) })
+}
+
+// readXattr calls read a first time to retrieve the needed size and a second time with an appropriate buffer.
+//
+// It retries as long as the value grows between the two calls.
+func readXattr(read func(dest []byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := read(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+
+		buf := make([]byte, size)
+		n, err := read(buf)
+		if errors.Is(err, syscall.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
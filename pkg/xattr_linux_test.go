@@ -0,0 +1,187 @@
+package filesystem_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	filesystem "github.com/kilianpaquier/filesystem/pkg"
+)
+
+func TestCopyFile_Xattrs(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "file.txt")
+	require.NoError(t, os.WriteFile(src, []byte("hey file"), filesystem.RwRR))
+
+	err := syscall.Setxattr(src, "user.origin", []byte("generator"), 0)
+	if errors.Is(err, syscall.ENOTSUP) {
+		t.Skip("extended attributes not supported by temporary directory filesystem")
+	}
+	require.NoError(t, err)
+	require.NoError(t, syscall.Setxattr(src, "user.other.name", []byte("value"), 0))
+
+	getxattr := func(name, attr string) (string, error) {
+		buf := make([]byte, 64)
+		n, err := syscall.Getxattr(name, attr, buf)
+		if err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	}
+
+	t.Run("success_no_xattrs", func(t *testing.T) {
+		// Arrange
+		dest := filepath.Join(tmp, "no_xattrs.txt")
+
+		// Act
+		err := filesystem.CopyFile(src, dest)
+
+		// Assert
+		require.NoError(t, err)
+		_, err = getxattr(dest, "user.origin")
+		assert.ErrorIs(t, err, syscall.ENODATA)
+	})
+
+	t.Run("success_user_namespace", func(t *testing.T) {
+		// Arrange
+		dest := filepath.Join(tmp, "user.txt")
+
+		// Act
+		err := filesystem.CopyFile(src, dest, filesystem.WithXattrs())
+
+		// Assert
+		require.NoError(t, err)
+		value, err := getxattr(dest, "user.origin")
+		require.NoError(t, err)
+		assert.Equal(t, "generator", value)
+		value, err = getxattr(dest, "user.other.name")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("success_allowlist", func(t *testing.T) {
+		// Arrange
+		dest := filepath.Join(tmp, "allowlist.txt")
+
+		// Act
+		err := filesystem.CopyFile(src, dest, filesystem.WithXattrs("user.other"))
+
+		// Assert
+		require.NoError(t, err)
+		_, err = getxattr(dest, "user.origin")
+		assert.ErrorIs(t, err, syscall.ENODATA)
+		value, err := getxattr(dest, "user.other.name")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("success_read_only_perm", func(t *testing.T) {
+		// Arrange
+		dest := filepath.Join(tmp, "read_only.txt")
+
+		// Act
+		err := filesystem.CopyFile(src, dest, filesystem.WithPerm(0o444), filesystem.WithXattrs())
+
+		// Assert
+		require.NoError(t, err)
+		value, err := getxattr(dest, "user.origin")
+		require.NoError(t, err)
+		assert.Equal(t, "generator", value)
+		info, err := os.Stat(dest)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o444), info.Mode().Perm())
+	})
+}
+
+// acl returns a POSIX ACL extended attribute value made of given entries (tag, permissions and id).
+func acl(entries ...[3]uint32) []byte {
+	value := binary.LittleEndian.AppendUint32(nil, 2) // POSIX ACL xattr version
+	for _, entry := range entries {
+		value = binary.LittleEndian.AppendUint16(value, uint16(entry[0]))
+		value = binary.LittleEndian.AppendUint16(value, uint16(entry[1]))
+		value = binary.LittleEndian.AppendUint32(value, entry[2])
+	}
+	return value
+}
+
+func TestCopyDir_Xattrs(t *testing.T) {
+	const (
+		undefined = 1<<32 - 1
+		userObj   = 0x01
+		user      = 0x02
+		groupObj  = 0x04
+		mask      = 0x10
+		other     = 0x20
+	)
+
+	srcdir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(srcdir, "sub"), filesystem.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "sub", "file.txt"), []byte("hey file"), filesystem.RwRR))
+
+	err := syscall.Setxattr(filepath.Join(srcdir, "sub"), "user.origin", []byte("generator"), 0)
+	if errors.Is(err, syscall.ENOTSUP) {
+		t.Skip("extended attributes not supported by temporary directory filesystem")
+	}
+	require.NoError(t, err)
+
+	defaultACL := acl([3]uint32{userObj, 7, undefined}, [3]uint32{groupObj, 5, undefined}, [3]uint32{other, 5, undefined})
+	err = syscall.Setxattr(filepath.Join(srcdir, "sub"), "system.posix_acl_default", defaultACL, 0)
+	if errors.Is(err, syscall.ENOTSUP) {
+		t.Skip("POSIX ACLs not supported by temporary directory filesystem")
+	}
+	require.NoError(t, err)
+	accessACL := acl([3]uint32{userObj, 6, undefined}, [3]uint32{user, 7, 1000}, [3]uint32{groupObj, 4, undefined},
+		[3]uint32{mask, 7, undefined}, [3]uint32{other, 4, undefined})
+	require.NoError(t, syscall.Setxattr(filepath.Join(srcdir, "sub", "file.txt"), "system.posix_acl_access", accessACL, 0))
+
+	getxattr := func(name, attr string) ([]byte, error) {
+		buf := make([]byte, 256)
+		n, err := syscall.Getxattr(name, attr, buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+
+	t.Run("success_dirs", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithXattrs())
+
+		// Assert
+		require.NoError(t, err)
+		value, err := getxattr(filepath.Join(destdir, "sub"), "user.origin")
+		require.NoError(t, err)
+		assert.Equal(t, "generator", string(value))
+	})
+
+	t.Run("success_acls", func(t *testing.T) {
+		// Arrange
+		destdir := filepath.Join(t.TempDir(), "dest")
+
+		// Act
+		err := filesystem.CopyDir(srcdir, destdir, filesystem.WithXattrs("system.posix_acl_access", "system.posix_acl_default"))
+
+		// Assert
+		require.NoError(t, err)
+		value, err := getxattr(filepath.Join(destdir, "sub"), "system.posix_acl_default")
+		require.NoError(t, err)
+		assert.Equal(t, defaultACL, value)
+
+		// access ACL mask isn't capped by default permissions
+		value, err = getxattr(filepath.Join(destdir, "sub", "file.txt"), "system.posix_acl_access")
+		require.NoError(t, err)
+		assert.Equal(t, accessACL, value)
+		info, err := os.Stat(filepath.Join(destdir, "sub", "file.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o674), info.Mode().Perm())
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package filesystem
+
+// copyXattrs is not supported outside Linux, extended attributes are never copied.
+func copyXattrs(_, _ string, _ func(string) bool) error {
+	return nil
+}